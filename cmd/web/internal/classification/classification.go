@@ -0,0 +1,118 @@
+// Package classification measures how well scores separate two classes.
+package classification
+
+import (
+	"math"
+	"sort"
+)
+
+// ConfusionMatrix counts the outcomes of classifying at a threshold.
+type ConfusionMatrix struct {
+	TruePositives  int
+	FalsePositives int
+	TrueNegatives  int
+	FalseNegatives int
+}
+
+// Confusion classifies every score at or above threshold as positive and
+// compares the result with actual.
+func Confusion(actual []bool, scores []float64, threshold float64) ConfusionMatrix {
+	var m ConfusionMatrix
+	for i, positive := range actual {
+		predicted := scores[i] >= threshold
+		switch {
+		case predicted && positive:
+			m.TruePositives++
+		case predicted:
+			m.FalsePositives++
+		case positive:
+			m.FalseNegatives++
+		default:
+			m.TrueNegatives++
+		}
+	}
+	return m
+}
+
+// Accuracy is the share of correct classifications.
+func (m ConfusionMatrix) Accuracy() float64 {
+	total := m.TruePositives + m.FalsePositives + m.TrueNegatives + m.FalseNegatives
+	return ratio(m.TruePositives+m.TrueNegatives, total)
+}
+
+// Precision is the share of predicted positives that are positive.
+func (m ConfusionMatrix) Precision() float64 {
+	return ratio(m.TruePositives, m.TruePositives+m.FalsePositives)
+}
+
+// Recall, or sensitivity, is the share of positives predicted positive.
+func (m ConfusionMatrix) Recall() float64 {
+	return ratio(m.TruePositives, m.TruePositives+m.FalseNegatives)
+}
+
+// Specificity is the share of negatives predicted negative.
+func (m ConfusionMatrix) Specificity() float64 {
+	return ratio(m.TrueNegatives, m.TrueNegatives+m.FalsePositives)
+}
+
+// ratio returns a/b, or 0 when b is 0.
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+// ROCPoint is one threshold of a receiver operating characteristic curve.
+type ROCPoint struct {
+	Threshold         float64
+	FalsePositiveRate float64
+	TruePositiveRate  float64
+}
+
+// ROC returns the ROC curve of scores, from the strictest threshold at
+// (0, 0) to the loosest at (1, 1). Tied scores make a single step.
+func ROC(actual []bool, scores []float64) []ROCPoint {
+	order := make([]int, len(scores))
+	positives, negatives := 0, 0
+	for i := range order {
+		order[i] = i
+		if actual[i] {
+			positives++
+		} else {
+			negatives++
+		}
+	}
+	sort.Slice(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	// Above every score nothing is classified positive
+	curve := []ROCPoint{{Threshold: math.Inf(1)}}
+	tp, fp := 0, 0
+	for i, idx := range order {
+		if actual[idx] {
+			tp++
+		} else {
+			fp++
+		}
+		if i+1 < len(order) && scores[order[i+1]] == scores[idx] {
+			continue
+		}
+		curve = append(curve, ROCPoint{
+			Threshold:         scores[idx],
+			FalsePositiveRate: ratio(fp, negatives),
+			TruePositiveRate:  ratio(tp, positives),
+		})
+	}
+	return curve
+}
+
+// AUC returns the area under an ROC curve by the trapezoidal rule. It equals
+// the probability that a random positive scores above a random negative.
+func AUC(curve []ROCPoint) float64 {
+	area := 0.0
+	for i := 1; i < len(curve); i++ {
+		width := curve[i].FalsePositiveRate - curve[i-1].FalsePositiveRate
+		area += width * (curve[i].TruePositiveRate + curve[i-1].TruePositiveRate) / 2
+	}
+	return area
+}
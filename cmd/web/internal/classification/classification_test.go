@@ -0,0 +1,77 @@
+package classification
+
+import (
+	"math"
+	"testing"
+)
+
+var (
+	actual = []bool{true, true, false, true, false, false}
+	scores = []float64{0.9, 0.8, 0.7, 0.6, 0.4, 0.2}
+)
+
+func TestConfusion(t *testing.T) {
+	m := Confusion(actual, scores, 0.65)
+	want := ConfusionMatrix{TruePositives: 2, FalsePositives: 1, TrueNegatives: 2, FalseNegatives: 1}
+	if m != want {
+		t.Fatalf("Confusion = %+v, want %+v", m, want)
+	}
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"accuracy", m.Accuracy(), 4.0 / 6},
+		{"precision", m.Precision(), 2.0 / 3},
+		{"recall", m.Recall(), 2.0 / 3},
+		{"specificity", m.Specificity(), 2.0 / 3},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-12 {
+			t.Errorf("%s = %g, want %g", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// Nothing predicted positive leaves the precision at zero
+	if p := Confusion(actual, scores, 1).Precision(); p != 0 {
+		t.Errorf("precision with no positive predictions = %g, want 0", p)
+	}
+	// The threshold itself counts as positive
+	if m := Confusion(actual, scores, 0.9); m.TruePositives != 1 {
+		t.Errorf("at the top score TruePositives = %d, want 1", m.TruePositives)
+	}
+}
+
+func TestROC(t *testing.T) {
+	curve := ROC(actual, scores)
+	want := [][2]float64{{0, 0}, {0, 1.0 / 3}, {0, 2.0 / 3}, {1.0 / 3, 2.0 / 3}, {1.0 / 3, 1}, {2.0 / 3, 1}, {1, 1}}
+	if len(curve) != len(want) {
+		t.Fatalf("ROC has %d points, want %d", len(curve), len(want))
+	}
+	for i, w := range want {
+		if math.Abs(curve[i].FalsePositiveRate-w[0]) > 1e-12 || math.Abs(curve[i].TruePositiveRate-w[1]) > 1e-12 {
+			t.Errorf("point %d = (%g, %g), want (%g, %g)", i, curve[i].FalsePositiveRate, curve[i].TruePositiveRate, w[0], w[1])
+		}
+	}
+	if !math.IsInf(curve[0].Threshold, 1) {
+		t.Errorf("the first threshold is %g, want +Inf", curve[0].Threshold)
+	}
+	// 8 of the 9 positive-negative pairs are ordered correctly
+	if auc := AUC(curve); math.Abs(auc-8.0/9) > 1e-12 {
+		t.Errorf("AUC = %g, want 8/9", auc)
+	}
+}
+
+func TestROCTies(t *testing.T) {
+	// Tied scores make one diagonal step, worth half a pair each
+	curve := ROC([]bool{true, false, true, false}, []float64{0.5, 0.5, 0.9, 0.1})
+	if len(curve) != 4 {
+		t.Fatalf("ROC has %d points, want 4", len(curve))
+	}
+	if auc := AUC(curve); math.Abs(auc-0.875) > 1e-12 {
+		t.Errorf("AUC = %g, want 0.875", auc)
+	}
+	if auc := AUC(ROC([]bool{true, false}, []float64{0.3, 0.3})); auc != 0.5 {
+		t.Errorf("AUC of equal scores = %g, want 0.5", auc)
+	}
+}
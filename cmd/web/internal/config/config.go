@@ -12,11 +12,12 @@ type Config struct {
 }
 
 type AppConfig struct {
-	UseCache      bool
-	Config        Config
-	InfoLog       *log.Logger
-	ErrorLog      *log.Logger
-	TemplateCache map[string]*template.Template
-	Version       string
-	InProduction  bool
+	UseCache       bool
+	Config         Config
+	InfoLog        *log.Logger
+	ErrorLog       *log.Logger
+	TemplateCache  map[string]*template.Template
+	Version        string
+	InProduction   bool
+	MaxUploadBytes int64
 }
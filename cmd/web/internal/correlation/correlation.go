@@ -0,0 +1,187 @@
+// Package correlation measures the association between every pair of
+// columns of a table, with Pearson's linear correlation and Spearman's and
+// Kendall's rank correlations, and tests each coefficient against zero.
+package correlation
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+var (
+	// ErrTooFew is returned when a table has fewer than 3 rows, so no
+	// coefficient can be tested.
+	ErrTooFew = errors.New("correlation: too few rows")
+	// ErrLength is returned when the columns differ in length.
+	ErrLength = errors.New("correlation: columns differ in length")
+	// ErrConstant is returned when a column does not vary, so its
+	// correlations are undefined.
+	ErrConstant = errors.New("correlation: a column is constant")
+)
+
+// Method is a correlation coefficient.
+type Method string
+
+const (
+	Pearson  Method = "pearson"
+	Spearman Method = "spearman"
+	Kendall  Method = "kendall"
+)
+
+// Methods lists every correlation coefficient.
+var Methods = []Method{Pearson, Spearman, Kendall}
+
+// Matrix holds the coefficients of one method between every pair of
+// columns, with the p-values of the two sided tests that they are zero.
+// The diagonal has coefficient 1 and p-value 0.
+type Matrix struct {
+	Method       Method
+	Coefficients *mat.SymDense
+	P            *mat.SymDense
+}
+
+// check returns the number of rows of the columns.
+func check(columns [][]float64) (int, error) {
+	n := len(columns[0])
+	for _, c := range columns[1:] {
+		if len(c) != n {
+			return 0, ErrLength
+		}
+	}
+	if n < 3 {
+		return 0, ErrTooFew
+	}
+	for _, c := range columns {
+		if stat.Variance(c, nil) == 0 {
+			return 0, ErrConstant
+		}
+	}
+	return n, nil
+}
+
+// dense returns the columns as the columns of a matrix, one row per
+// observation.
+func dense(columns [][]float64) *mat.Dense {
+	m := mat.NewDense(len(columns[0]), len(columns), nil)
+	for j, c := range columns {
+		m.SetCol(j, c)
+	}
+	return m
+}
+
+// Covariance returns the sample covariance matrix of the columns.
+func Covariance(columns [][]float64) (*mat.SymDense, error) {
+	if _, err := check(columns); err != nil {
+		return nil, err
+	}
+	covariance := mat.NewSymDense(len(columns), nil)
+	stat.CovarianceMatrix(covariance, dense(columns), nil)
+	return covariance, nil
+}
+
+// Correlate returns the correlation matrix of the columns by method.
+func Correlate(columns [][]float64, method Method) (Matrix, error) {
+	n, err := check(columns)
+	if err != nil {
+		return Matrix{}, err
+	}
+
+	k := len(columns)
+	coefficients := mat.NewSymDense(k, nil)
+	switch method {
+	case Pearson:
+		stat.CorrelationMatrix(coefficients, dense(columns), nil)
+	case Spearman:
+		// Spearman's coefficient is Pearson's of the ranks
+		ranks := make([][]float64, k)
+		for j, c := range columns {
+			ranks[j] = Ranks(c)
+		}
+		stat.CorrelationMatrix(coefficients, dense(ranks), nil)
+	case Kendall:
+		for i := 0; i < k; i++ {
+			coefficients.SetSym(i, i, 1)
+			for j := i + 1; j < k; j++ {
+				coefficients.SetSym(i, j, TauB(columns[i], columns[j]))
+			}
+		}
+	}
+
+	p := mat.NewSymDense(k, nil)
+	for i := 0; i < k; i++ {
+		for j := i + 1; j < k; j++ {
+			p.SetSym(i, j, pValue(method, coefficients.At(i, j), n))
+		}
+	}
+	return Matrix{Method: method, Coefficients: coefficients, P: p}, nil
+}
+
+// pValue returns the two sided p-value of coefficient r of n rows. Pearson's
+// and Spearman's coefficients are tested with Student's t distribution of
+// n-2 degrees of freedom, Kendall's with its normal approximation without
+// a correction for ties.
+func pValue(method Method, r float64, n int) float64 {
+	if math.Abs(r) >= 1 {
+		return 0
+	}
+	nf := float64(n)
+	if method == Kendall {
+		z := 3 * r * math.Sqrt(nf*(nf-1)) / math.Sqrt(2*(2*nf+5))
+		return 2 * distuv.UnitNormal.CDF(-math.Abs(z))
+	}
+	t := r * math.Sqrt((nf-2)/(1-r*r))
+	return 2 * distuv.StudentsT{Mu: 0, Sigma: 1, Nu: nf - 2}.CDF(-math.Abs(t))
+}
+
+// Ranks returns the rank of every value of x, starting at 1. Tied values
+// share the average of their ranks.
+func Ranks(x []float64) []float64 {
+	order := make([]int, len(x))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return x[order[a]] < x[order[b]] })
+
+	ranks := make([]float64, len(x))
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && x[order[end]] == x[order[start]] {
+			end++
+		}
+		// Ranks start+1 to end average to their midpoint
+		rank := float64(start+end+1) / 2
+		for _, i := range order[start:end] {
+			ranks[i] = rank
+		}
+		start = end
+	}
+	return ranks
+}
+
+// TauB returns Kendall's tau-b of x and y, which unlike stat.Kendall
+// corrects for ties in either sample.
+func TauB(x, y []float64) float64 {
+	concordant, discordant, tiedX, tiedY := 0.0, 0.0, 0.0, 0.0
+	for i := range x {
+		for j := i + 1; j < len(x); j++ {
+			dx, dy := x[j]-x[i], y[j]-y[i]
+			switch {
+			case dx == 0 && dy == 0:
+			case dx == 0:
+				tiedX++
+			case dy == 0:
+				tiedY++
+			case (dx > 0) == (dy > 0):
+				concordant++
+			default:
+				discordant++
+			}
+		}
+	}
+	return (concordant - discordant) / math.Sqrt((concordant+discordant+tiedX)*(concordant+discordant+tiedY))
+}
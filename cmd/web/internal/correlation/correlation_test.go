@@ -0,0 +1,130 @@
+package correlation
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// The speed and stopping distance of 50 cars, as shipped with R.
+var (
+	speed = []float64{4, 4, 7, 7, 8, 9, 10, 10, 10, 11, 11, 12, 12, 12, 12, 13, 13, 13, 13, 14, 14, 14, 14, 15, 15, 15, 16, 16, 17, 17, 17, 18, 18, 18, 18, 19, 19, 19, 20, 20, 20, 20, 20, 22, 23, 24, 24, 24, 24, 25}
+	dist  = []float64{2, 10, 4, 22, 16, 10, 18, 26, 34, 17, 28, 14, 20, 24, 28, 26, 34, 34, 46, 26, 36, 60, 80, 20, 26, 54, 32, 40, 32, 40, 50, 42, 56, 76, 84, 36, 46, 68, 32, 48, 52, 56, 64, 66, 54, 70, 92, 93, 120, 85}
+)
+
+func near(got, want, tol float64) bool {
+	return got == want || math.Abs(got-want) <= tol*math.Max(1, math.Abs(want))
+}
+
+// The reference values are those of R's cor and cor.test on the cars data.
+func TestCorrelate(t *testing.T) {
+	tests := []struct {
+		method Method
+		r      float64
+	}{
+		{Pearson, 0.8068949},
+		{Spearman, 0.8303568},
+		{Kendall, 0.6689901},
+	}
+	for _, tt := range tests {
+		m, err := Correlate([][]float64{speed, dist}, tt.method)
+		if err != nil {
+			t.Errorf("%s: %v", tt.method, err)
+			continue
+		}
+		if r := m.Coefficients.At(0, 1); !near(r, tt.r, 1e-6) || m.Coefficients.At(0, 0) != 1 {
+			t.Errorf("%s: r = %g, want %g", tt.method, r, tt.r)
+		}
+		if p := m.P.At(0, 1); !(p > 0 && p < 1e-6) {
+			t.Errorf("%s: p = %g, want a small positive p-value", tt.method, p)
+		}
+	}
+
+	// Pearson's test is the t-test of the regression slope
+	m, err := Correlate([][]float64{speed, dist}, Pearson)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := m.P.At(0, 1); !near(p, 1.489836e-12, 1e-6) {
+		t.Errorf("Pearson p = %g, want 1.489836e-12", p)
+	}
+}
+
+func TestCovariance(t *testing.T) {
+	c, err := Covariance([][]float64{speed, dist})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]float64{{27.95918, 109.9469}, {109.9469, 664.0608}}
+	for i := range want {
+		for j := range want[i] {
+			if !near(c.At(i, j), want[i][j], 1e-6) {
+				t.Errorf("Covariance[%d][%d] = %g, want %g", i, j, c.At(i, j), want[i][j])
+			}
+		}
+	}
+}
+
+func TestCorrelateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns [][]float64
+		want    error
+	}{
+		{"lengths", [][]float64{{1, 2, 3}, {1, 2}}, ErrLength},
+		{"two rows", [][]float64{{1, 2}, {2, 1}}, ErrTooFew},
+		{"constant", [][]float64{{1, 2, 3}, {5, 5, 5}}, ErrConstant},
+	}
+	for _, tt := range tests {
+		if _, err := Correlate(tt.columns, Pearson); err != tt.want {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+		if _, err := Covariance(tt.columns); err != tt.want {
+			t.Errorf("%s: Covariance error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestPerfectCorrelation(t *testing.T) {
+	m, err := Correlate([][]float64{{1, 2, 3, 4}, {8, 6, 4, 2}}, Spearman)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, p := m.Coefficients.At(0, 1), m.P.At(0, 1); !near(r, -1, 1e-12) || p != 0 {
+		t.Errorf("r = %g, p = %g; want -1, 0", r, p)
+	}
+}
+
+func TestRanks(t *testing.T) {
+	tests := []struct {
+		x, want []float64
+	}{
+		{[]float64{30, 10, 20}, []float64{3, 1, 2}},
+		{[]float64{5, 1, 5, 3}, []float64{3.5, 1, 3.5, 2}},
+		{[]float64{2, 2, 2}, []float64{2, 2, 2}},
+		{nil, []float64{}},
+	}
+	for _, tt := range tests {
+		if got := Ranks(tt.x); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Ranks(%v) = %v, want %v", tt.x, got, tt.want)
+		}
+	}
+}
+
+func TestTauB(t *testing.T) {
+	tests := []struct {
+		x, y []float64
+		want float64
+	}{
+		{[]float64{1, 2, 3, 4, 5}, []float64{3, 4, 1, 2, 5}, 0.2},
+		{[]float64{1, 2, 3}, []float64{3, 2, 1}, -1},
+		// Ties in both samples: 3 concordant, 1 discordant, one pair
+		// tied in each
+		{[]float64{1, 2, 2, 3}, []float64{1, 3, 2, 2}, 0.4},
+	}
+	for _, tt := range tests {
+		if got := TauB(tt.x, tt.y); !near(got, tt.want, 1e-12) {
+			t.Errorf("TauB(%v, %v) = %g, want %g", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrEmpty is returned when an upload contains no numbers.
+var ErrEmpty = errors.New("dataset contains no numbers")
+
+// ParseNumbers reads a flat list of numbers from a JSON array or from CSV.
+// The format is taken from contentType, or guessed from the body when the
+// content type is neither JSON nor CSV.
+func ParseNumbers(body []byte, contentType string) ([]float64, error) {
+	var (
+		values []float64
+		err    error
+	)
+
+	switch {
+	case strings.Contains(contentType, "json"):
+		values, err = parseJSON(body)
+	case strings.Contains(contentType, "csv"):
+		values, err = parseCSV(body)
+	case bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")):
+		values, err = parseJSON(body)
+	default:
+		values, err = parseCSV(body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) == 0 {
+		return nil, ErrEmpty
+	}
+
+	for i, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("value %d is not a finite number", i+1)
+		}
+	}
+
+	return values, nil
+}
+
+func parseJSON(body []byte) ([]float64, error) {
+	var values []float64
+	if err := json.Unmarshal(body, &values); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of numbers: %w", err)
+	}
+	return values, nil
+}
+
+// parseCSV reads every cell of the CSV as a number. A first row that is not
+// numeric is treated as a header and skipped.
+func parseCSV(body []byte) ([]float64, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	first := 1
+	if len(records) > 0 && !numericRow(records[0]) {
+		records = records[1:]
+		first = 2
+	}
+
+	var values []float64
+	for row, record := range records {
+		for col, cell := range record {
+			cell = strings.TrimSpace(cell)
+			if cell == "" {
+				continue
+			}
+
+			v, err := strconv.ParseFloat(cell, 64)
+			if err != nil {
+				return nil, fmt.Errorf("row %d, column %d: %q is not a number", row+first, col+1, cell)
+			}
+			values = append(values, v)
+		}
+	}
+
+	return values, nil
+}
+
+func numericRow(record []string) bool {
+	for _, cell := range record {
+		cell = strings.TrimSpace(cell)
+		if cell == "" {
+			continue
+		}
+		if _, err := strconv.ParseFloat(cell, 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Table is a set of equally long, named numeric columns.
+type Table struct {
+	Names   []string
+	Columns [][]float64
+}
+
+// Rows returns the number of rows in the table.
+func (t *Table) Rows() int {
+	if len(t.Columns) == 0 {
+		return 0
+	}
+	return len(t.Columns[0])
+}
+
+// Column returns the column with the given name, or nil.
+func (t *Table) Column(name string) []float64 {
+	for i, n := range t.Names {
+		if n == name {
+			return t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// ParseTable reads named columns from CSV or from a JSON object that maps
+// each column name to an array of numbers. A CSV without a header row gets
+// the column names x1, x2 and so on. The format is chosen like ParseNumbers.
+func ParseTable(body []byte, contentType string) (*Table, error) {
+	var (
+		table *Table
+		err   error
+	)
+
+	switch {
+	case strings.Contains(contentType, "json"):
+		table, err = parseJSONTable(body)
+	case strings.Contains(contentType, "csv"):
+		table, err = parseCSVTable(body)
+	case bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")):
+		table, err = parseJSONTable(body)
+	default:
+		table, err = parseCSVTable(body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if table.Rows() == 0 {
+		return nil, ErrEmpty
+	}
+
+	seen := make(map[string]bool)
+	for i, column := range table.Columns {
+		name := table.Names[i]
+		if seen[name] {
+			return nil, fmt.Errorf("column %q appears more than once", name)
+		}
+		seen[name] = true
+
+		if len(column) != table.Rows() {
+			return nil, fmt.Errorf("column %q has %d values, expected %d", name, len(column), table.Rows())
+		}
+		for row, v := range column {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, fmt.Errorf("column %q, row %d is not a finite number", name, row+1)
+			}
+		}
+	}
+
+	return table, nil
+}
+
+// parseJSONTable decodes the object token by token, so the columns keep the
+// order they were written in.
+func parseJSONTable(body []byte) (*Table, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errors.New("expected a JSON object of numeric columns")
+	}
+
+	table := &Table{}
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+
+		var column []float64
+		if err := decoder.Decode(&column); err != nil {
+			return nil, fmt.Errorf("column %q must be an array of numbers: %w", tok, err)
+		}
+		table.Names = append(table.Names, tok.(string))
+		table.Columns = append(table.Columns, column)
+	}
+
+	return table, nil
+}
+
+func parseCSVTable(body []byte) (*Table, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, ErrEmpty
+	}
+
+	table := &Table{}
+	first := 1
+	if numericRow(records[0]) {
+		for i := range records[0] {
+			table.Names = append(table.Names, fmt.Sprintf("x%d", i+1))
+		}
+	} else {
+		for _, name := range records[0] {
+			table.Names = append(table.Names, strings.TrimSpace(name))
+		}
+		records = records[1:]
+		first = 2
+	}
+
+	table.Columns = make([][]float64, len(table.Names))
+	for row, record := range records {
+		for col, cell := range record {
+			cell = strings.TrimSpace(cell)
+			v, err := strconv.ParseFloat(cell, 64)
+			if err != nil {
+				return nil, fmt.Errorf("row %d, column %d: %q is not a number", row+first, col+1, cell)
+			}
+			table.Columns[col] = append(table.Columns[col], v)
+		}
+	}
+
+	return table, nil
+}
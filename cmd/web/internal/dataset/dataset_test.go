@@ -0,0 +1,113 @@
+package dataset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNumbers(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        []float64
+	}{
+		{"json", "[1, 2.5, -3]", "application/json", []float64{1, 2.5, -3}},
+		{"json guessed", "  [4, 5]", "", []float64{4, 5}},
+		{"csv", "1,2\n3,4\n", "text/csv", []float64{1, 2, 3, 4}},
+		{"csv header", "height\n170\n182\n", "text/csv", []float64{170, 182}},
+		{"csv guessed", "1, 2,,3", "text/plain", []float64{1, 2, 3}},
+		{"csv ragged", "1\n2,3\n", "text/csv", []float64{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		got, err := ParseNumbers([]byte(tt.body), tt.contentType)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ParseNumbers = %v, %v; want %v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseNumbersErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+	}{
+		{"empty json", "[]", "application/json"},
+		{"empty csv", "", "text/csv"},
+		{"header only", "height\n", "text/csv"},
+		{"json object", `{"x": [1]}`, "application/json"},
+		{"json strings", `["a"]`, "application/json"},
+		{"csv word", "1,2\n3,x\n", "text/csv"},
+		{"csv NaN", "1,NaN", "text/csv"},
+		{"csv Inf", "1\n-Inf", "text/csv"},
+	}
+	for _, tt := range tests {
+		if got, err := ParseNumbers([]byte(tt.body), tt.contentType); err == nil {
+			t.Errorf("%s: ParseNumbers = %v, want an error", tt.name, got)
+		}
+	}
+	if _, err := ParseNumbers([]byte("[]"), "application/json"); err != ErrEmpty {
+		t.Errorf("empty: error %v, want %v", err, ErrEmpty)
+	}
+}
+
+func TestParseTable(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        *Table
+	}{
+		{"json keeps order", `{"y": [1, 2], "x": [3, 4]}`, "application/json", &Table{Names: []string{"y", "x"}, Columns: [][]float64{{1, 2}, {3, 4}}}},
+		{"json guessed", ` {"a": [5]}`, "", &Table{Names: []string{"a"}, Columns: [][]float64{{5}}}},
+		{"csv header", "speed, dist\n4,2\n7,4\n", "text/csv", &Table{Names: []string{"speed", "dist"}, Columns: [][]float64{{4, 7}, {2, 4}}}},
+		{"csv no header", "4,2\n7,4\n", "text/csv", &Table{Names: []string{"x1", "x2"}, Columns: [][]float64{{4, 7}, {2, 4}}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseTable([]byte(tt.body), tt.contentType)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ParseTable = %+v, %v; want %+v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseTableErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+	}{
+		{"empty json", `{}`, "application/json"},
+		{"empty csv", "", "text/csv"},
+		{"header only", "x,y\n", "text/csv"},
+		{"json array", `[1, 2]`, "application/json"},
+		{"json lengths", `{"x": [1, 2], "y": [3]}`, "application/json"},
+		{"json duplicate", `{"x": [1], "x": [2]}`, "application/json"},
+		{"csv duplicate", "x,x\n1,2\n", "text/csv"},
+		{"csv ragged", "x,y\n1,2\n3\n", "text/csv"},
+		{"csv word", "x,y\n1,a\n", "text/csv"},
+		{"csv NaN", "x,y\n1,NaN\n", "text/csv"},
+	}
+	for _, tt := range tests {
+		if got, err := ParseTable([]byte(tt.body), tt.contentType); err == nil {
+			t.Errorf("%s: ParseTable = %+v, want an error", tt.name, got)
+		}
+	}
+}
+
+func TestTable(t *testing.T) {
+	table := &Table{Names: []string{"x", "y"}, Columns: [][]float64{{1, 2, 3}, {4, 5, 6}}}
+	if table.Rows() != 3 {
+		t.Errorf("Rows() = %d, want 3", table.Rows())
+	}
+	if got := table.Column("y"); !reflect.DeepEqual(got, []float64{4, 5, 6}) {
+		t.Errorf("Column(y) = %v", got)
+	}
+	if got := table.Column("z"); got != nil {
+		t.Errorf("Column(z) = %v, want nil", got)
+	}
+	if rows := (&Table{}).Rows(); rows != 0 {
+		t.Errorf("empty Rows() = %d, want 0", rows)
+	}
+}
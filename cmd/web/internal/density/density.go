@@ -0,0 +1,254 @@
+// Package density estimates the density of a sample with a kernel, and
+// picks kernel bandwidths and histogram bin counts by the usual rules of
+// thumb.
+package density
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+)
+
+var (
+	// ErrTooFew is returned when a sample has fewer than 2 values, so its
+	// spread is undefined.
+	ErrTooFew = errors.New("density: too few values")
+	// ErrConstant is returned when the values of a sample are all equal,
+	// so no rule of thumb gives a bandwidth.
+	ErrConstant = errors.New("density: values are constant")
+	// ErrBandwidth is returned for a bandwidth that is not positive.
+	ErrBandwidth = errors.New("density: bandwidth must be positive")
+)
+
+// Kernel is a density symmetric about zero that is spread over every value
+// of a sample.
+type Kernel struct {
+	// Name is the URL name of the kernel, such as "epanechnikov".
+	Name  string
+	Title string
+	Prob  func(u float64) float64
+	// Support is the half width of the interval outside of which Prob is
+	// zero, or +Inf.
+	Support float64
+	// StdDev is the standard deviation of Prob. Kernels are scaled by it,
+	// so a bandwidth is the standard deviation of the scaled kernel
+	// whichever kernel is used, and the rules of thumb apply to them all.
+	StdDev float64
+}
+
+var (
+	Gaussian = Kernel{
+		Name:    "gaussian",
+		Title:   "Gaussian",
+		Prob:    func(u float64) float64 { return math.Exp(-u*u/2) / math.Sqrt(2*math.Pi) },
+		Support: math.Inf(1),
+		StdDev:  1,
+	}
+	Epanechnikov = Kernel{
+		Name:    "epanechnikov",
+		Title:   "Epanechnikov",
+		Prob:    compact(func(u float64) float64 { return 0.75 * (1 - u*u) }),
+		Support: 1,
+		StdDev:  math.Sqrt(1.0 / 5),
+	}
+	Uniform = Kernel{
+		Name:    "uniform",
+		Title:   "Uniform",
+		Prob:    compact(func(u float64) float64 { return 0.5 }),
+		Support: 1,
+		StdDev:  math.Sqrt(1.0 / 3),
+	}
+	Triangular = Kernel{
+		Name:    "triangular",
+		Title:   "Triangular",
+		Prob:    compact(func(u float64) float64 { return 1 - math.Abs(u) }),
+		Support: 1,
+		StdDev:  math.Sqrt(1.0 / 6),
+	}
+	Biweight = Kernel{
+		Name:    "biweight",
+		Title:   "Biweight",
+		Prob:    compact(func(u float64) float64 { return 15.0 / 16 * (1 - u*u) * (1 - u*u) }),
+		Support: 1,
+		StdDev:  math.Sqrt(1.0 / 7),
+	}
+	Cosine = Kernel{
+		Name:    "cosine",
+		Title:   "Cosine",
+		Prob:    compact(func(u float64) float64 { return math.Pi / 4 * math.Cos(math.Pi/2*u) }),
+		Support: 1,
+		StdDev:  math.Sqrt(1 - 8/(math.Pi*math.Pi)),
+	}
+)
+
+// Kernels lists every kernel.
+var Kernels = []Kernel{Gaussian, Epanechnikov, Uniform, Triangular, Biweight, Cosine}
+
+// LookupKernel returns the kernel with the given name.
+func LookupKernel(name string) (Kernel, bool) {
+	for _, k := range Kernels {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return Kernel{}, false
+}
+
+// compact restricts f to [-1, 1].
+func compact(f func(u float64) float64) func(u float64) float64 {
+	return func(u float64) float64 {
+		if u < -1 || u > 1 {
+			return 0
+		}
+		return f(u)
+	}
+}
+
+// Rule is a rule of thumb for the bandwidth of a kernel density estimate.
+type Rule string
+
+const (
+	// Silverman's rule, 0.9·min(s, IQR/1.34)·n^(-1/5), is robust to
+	// outliers and heavy tails.
+	Silverman Rule = "silverman"
+	// Scott's rule, 1.06·s·n^(-1/5), is optimal for normal samples.
+	Scott Rule = "scott"
+)
+
+// Rules lists every bandwidth rule.
+var Rules = []Rule{Silverman, Scott}
+
+// Bandwidth returns the bandwidth of x by rule.
+func Bandwidth(x []float64, rule Rule) (float64, error) {
+	if len(x) < 2 {
+		return 0, ErrTooFew
+	}
+	n := float64(len(x))
+	spread := stat.StdDev(x, nil)
+	if rule == Silverman {
+		// The IQR of a normal sample is 1.34 standard deviations; it
+		// only replaces the standard deviation when it is smaller
+		if r := iqr(x) / 1.34; r > 0 && r < spread {
+			spread = r
+		}
+	}
+	if spread == 0 {
+		return 0, ErrConstant
+	}
+	factor := 1.06
+	if rule == Silverman {
+		factor = 0.9
+	}
+	return factor * spread * math.Pow(n, -0.2), nil
+}
+
+// BinRule is a rule for the number of bins of a histogram.
+type BinRule string
+
+const (
+	// Sturges' rule, ⌈log₂ n⌉ + 1, suits small normal samples.
+	Sturges BinRule = "sturges"
+	// The Freedman–Diaconis rule makes bins 2·IQR·n^(-1/3) wide, so
+	// large and skewed samples get enough of them.
+	FreedmanDiaconis BinRule = "freedman-diaconis"
+)
+
+// BinRules lists every bin rule.
+var BinRules = []BinRule{Sturges, FreedmanDiaconis}
+
+// Bins returns the number of histogram bins for x by rule, at least 1.
+// The Freedman–Diaconis rule falls back to Sturges' when the IQR of x is
+// zero.
+func Bins(x []float64, rule BinRule) int {
+	n := float64(len(x))
+	if n < 2 {
+		return 1
+	}
+	sturges := int(math.Ceil(math.Log2(n))) + 1
+	if rule == Sturges {
+		return sturges
+	}
+
+	width := 2 * iqr(x) / math.Cbrt(n)
+	span := floats.Max(x) - floats.Min(x)
+	if width <= 0 || span <= 0 {
+		return sturges
+	}
+	return max(int(math.Ceil(span/width)), 1)
+}
+
+// iqr returns the interquartile range of x, by linear interpolation of a
+// sorted copy.
+func iqr(x []float64) float64 {
+	sorted := append([]float64(nil), x...)
+	sort.Float64s(sorted)
+	return stat.Quantile(0.75, stat.LinInterp, sorted, nil) - stat.Quantile(0.25, stat.LinInterp, sorted, nil)
+}
+
+// Estimate is a kernel density estimate of a sample.
+type Estimate struct {
+	Kernel    Kernel
+	Bandwidth float64
+	x         []float64
+	min, max  float64
+}
+
+// New returns the estimate of x with kernel k scaled to bandwidth h.
+func New(x []float64, k Kernel, h float64) (*Estimate, error) {
+	if len(x) == 0 {
+		return nil, ErrTooFew
+	}
+	if !(h > 0) || math.IsInf(h, 1) {
+		return nil, ErrBandwidth
+	}
+	return &Estimate{Kernel: k, Bandwidth: h, x: x, min: floats.Min(x), max: floats.Max(x)}, nil
+}
+
+// Range returns the interval where the estimate is worth drawing: the
+// range of the sample widened by 3 bandwidths, or by the support of a
+// compact kernel when that is narrower.
+func (e *Estimate) Range() (lo, hi float64) {
+	reach := e.Bandwidth * math.Min(3, e.Kernel.Support/e.Kernel.StdDev)
+	return e.min - reach, e.max + reach
+}
+
+// Grid returns the estimate at points evenly spaced values from lo to hi,
+// where points is at least 2. The sample is first shared out linearly
+// between as many evenly spaced nodes over its range, so the cost does not
+// grow with its size.
+func (e *Estimate) Grid(lo, hi float64, points int) []float64 {
+	step := (e.max - e.min) / float64(points-1)
+	weights := make([]float64, points)
+	for _, v := range e.x {
+		if step == 0 {
+			weights[0]++
+			continue
+		}
+		pos := (v - e.min) / step
+		i := min(int(pos), points-2)
+		frac := pos - float64(i)
+		weights[i] += 1 - frac
+		weights[i+1] += frac
+	}
+
+	// The kernel scaled to bandwidth h has density K(d·s/h)·s/h, where s
+	// is the standard deviation of K
+	scale := e.Kernel.StdDev / e.Bandwidth
+	n := float64(len(e.x))
+	density := make([]float64, points)
+	for i := range density {
+		at := lo + float64(i)*(hi-lo)/float64(points-1)
+		sum := 0.0
+		for j, w := range weights {
+			if w == 0 {
+				continue
+			}
+			sum += w * e.Kernel.Prob((at-(e.min+float64(j)*step))*scale)
+		}
+		density[i] = sum * scale / n
+	}
+	return density
+}
@@ -0,0 +1,155 @@
+package density
+
+import (
+	"math"
+	"testing"
+)
+
+func near(got, want, tol float64) bool {
+	return got == want || math.Abs(got-want) <= tol*math.Max(1, math.Abs(want))
+}
+
+// Every kernel must be a density with the standard deviation it claims,
+// since bandwidths are scaled by it.
+func TestKernels(t *testing.T) {
+	const steps = 200000
+	for _, k := range Kernels {
+		reach := math.Min(k.Support, 10)
+		dx := 2 * reach / steps
+		mass, variance := 0.0, 0.0
+		for i := 0; i < steps; i++ {
+			u := -reach + (float64(i)+0.5)*dx
+			p := k.Prob(u) * dx
+			mass += p
+			variance += u * u * p
+		}
+		if !near(mass, 1, 1e-6) || !near(math.Sqrt(variance), k.StdDev, 1e-6) {
+			t.Errorf("%s: mass %g, standard deviation %g; want 1, %g", k.Name, mass, math.Sqrt(variance), k.StdDev)
+		}
+		if k.Prob(reach+1) != 0 && !math.IsInf(k.Support, 1) {
+			t.Errorf("%s: Prob is not zero outside the support", k.Name)
+		}
+		if got, ok := LookupKernel(k.Name); !ok || got.Name != k.Name {
+			t.Errorf("LookupKernel(%q) = %v, %t", k.Name, got.Name, ok)
+		}
+	}
+	if _, ok := LookupKernel("no-such-kernel"); ok {
+		t.Error("LookupKernel of an unknown kernel succeeded")
+	}
+}
+
+// The Silverman value of 1:10 is R's bw.nrd0(1:10).
+func TestBandwidth(t *testing.T) {
+	tests := []struct {
+		name string
+		x    []float64
+		rule Rule
+		want float64
+	}{
+		{"silverman", []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, Silverman, 1.719286},
+		{"scott", []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, Scott, 2.024937},
+		// An outlier inflates the standard deviation but not the IQR
+		{"silverman outlier", []float64{1, 2, 3, 4, 100}, Silverman, 0.9 * 2.5 / 1.34 * math.Pow(5, -0.2)},
+		{"scott outlier", []float64{1, 2, 3, 4, 100}, Scott, 33.50998},
+		// With a zero IQR Silverman's rule keeps the standard deviation
+		{"silverman zero IQR", []float64{1, 1, 1, 1, 1, 1, 1, 1, 5}, Silverman, 0.9 * 4 / 3 * math.Pow(9, -0.2)},
+	}
+	for _, tt := range tests {
+		got, err := Bandwidth(tt.x, tt.rule)
+		if err != nil || !near(got, tt.want, 1e-6) {
+			t.Errorf("%s: Bandwidth = %g, %v; want %g", tt.name, got, err, tt.want)
+		}
+	}
+
+	if _, err := Bandwidth([]float64{1}, Scott); err != ErrTooFew {
+		t.Errorf("one value: error %v, want %v", err, ErrTooFew)
+	}
+	for _, rule := range Rules {
+		if _, err := Bandwidth([]float64{3, 3, 3}, rule); err != ErrConstant {
+			t.Errorf("%s of a constant: error %v, want %v", rule, err, ErrConstant)
+		}
+	}
+}
+
+func TestBins(t *testing.T) {
+	hundred := make([]float64, 100)
+	for i := range hundred {
+		hundred[i] = float64(i + 1)
+	}
+	tests := []struct {
+		name string
+		x    []float64
+		rule BinRule
+		want int
+	}{
+		{"sturges", hundred[:10], Sturges, 5},
+		{"sturges 8", hundred[:8], Sturges, 4},
+		{"freedman-diaconis", hundred, FreedmanDiaconis, 5},
+		{"freedman-diaconis zero IQR", []float64{1, 1, 1, 1, 1, 1, 1, 1, 9}, FreedmanDiaconis, 5},
+		{"one value", []float64{4}, FreedmanDiaconis, 1},
+		{"empty", nil, Sturges, 1},
+	}
+	for _, tt := range tests {
+		if got := Bins(tt.x, tt.rule); got != tt.want {
+			t.Errorf("%s: Bins = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	// The estimate of a single value is the kernel itself
+	e, err := New([]float64{2}, Gaussian, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lo, hi := e.Range()
+	if lo != 0.5 || hi != 3.5 {
+		t.Errorf("Range = [%g, %g], want [0.5, 3.5]", lo, hi)
+	}
+	grid := e.Grid(0, 4, 5)
+	for i, got := range grid {
+		u := (float64(i) - 2) / 0.5
+		if want := math.Exp(-u*u/2) / math.Sqrt(2*math.Pi) / 0.5; !near(got, want, 1e-12) {
+			t.Errorf("Grid[%d] = %g, want %g", i, got, want)
+		}
+	}
+
+	// A compact kernel reaches no further than its support
+	e, err = New([]float64{0, 1}, Uniform, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lo, hi := e.Range(); !near(lo, -math.Sqrt(3), 1e-12) || !near(hi, 1+math.Sqrt(3), 1e-12) {
+		t.Errorf("Range = [%g, %g], want ±√3 beyond the sample", lo, hi)
+	}
+
+	// The estimate is a density
+	x := []float64{1.2, 1.9, 2.3, 2.4, 3.8, 4.1, 5.5, 6.0}
+	for _, k := range Kernels {
+		e, err := New(x, k, 0.7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lo, hi := -5.0, 12.0
+		const points = 4001
+		grid := e.Grid(lo, hi, points)
+		mass := 0.0
+		for _, v := range grid {
+			mass += v * (hi - lo) / (points - 1)
+		}
+		if !near(mass, 1, 1e-3) {
+			t.Errorf("%s: the estimate integrates to %g", k.Name, mass)
+		}
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	if _, err := New(nil, Gaussian, 1); err != ErrTooFew {
+		t.Errorf("no values: error %v, want %v", err, ErrTooFew)
+	}
+	for _, h := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if _, err := New([]float64{1, 2}, Gaussian, h); err != ErrBandwidth {
+			t.Errorf("bandwidth %g: error %v, want %v", h, err, ErrBandwidth)
+		}
+	}
+}
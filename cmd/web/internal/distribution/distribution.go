@@ -0,0 +1,163 @@
+// Package distribution keeps a registry of the gonum distuv families that
+// the distribution explorer can build from query parameters.
+package distribution
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"golang.org/x/exp/rand"
+)
+
+// Distribution is the part of a distuv distribution the explorer uses.
+// Prob is the density of continuous and the mass of discrete families.
+// Continuous distributions must also have a Quantile method.
+type Distribution interface {
+	Prob(x float64) float64
+	CDF(x float64) float64
+	Survival(x float64) float64
+	Mean() float64
+	Variance() float64
+	Rand() float64
+}
+
+// Param describes one parameter of a family. Values must lie in
+// [Min, Max]; a Positive parameter must also be greater than zero.
+type Param struct {
+	Name     string
+	Default  float64
+	Min      float64
+	Max      float64
+	Integer  bool
+	Positive bool
+}
+
+// ParamError reports a parameter value the family cannot be built with.
+type ParamError struct {
+	Param   string
+	Message string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("%s %s", e.Param, e.Message)
+}
+
+// Family builds distributions of one kind.
+type Family struct {
+	// Name is the URL name of the family, such as "students-t".
+	Name     string
+	Title    string
+	Discrete bool
+	Params   []Param
+	// New builds the distribution from values given in the order of Params.
+	// Rand draws from src, or from the global source when src is nil. New
+	// returns a *ParamError when the values are inconsistent.
+	New func(params []float64, src rand.Source) (Distribution, error)
+	// Skew is the closed form skewness for families whose distuv type
+	// lacks a Skewness method. It may return NaN where it is undefined.
+	Skew func(params []float64) float64
+	// MLE returns the maximum likelihood estimates of the parameters from a
+	// sample, for families where they have a closed form. It returns
+	// ErrSupport when the sample lies outside the support of the family.
+	MLE func(x []float64) ([]float64, error)
+	// Start returns a starting point for the numeric maximization of the
+	// likelihood, such as the method of moments estimates, for families
+	// without MLE. Families with neither cannot be fitted.
+	Start func(x []float64) []float64
+}
+
+var families = map[string]Family{}
+
+// Register adds a family to the registry, replacing any family with the
+// same name.
+func Register(f Family) {
+	families[f.Name] = f
+}
+
+// Lookup returns the family with the given name.
+func Lookup(name string) (Family, bool) {
+	f, ok := families[name]
+	return f, ok
+}
+
+// Families returns every registered family, sorted by name.
+func Families() []Family {
+	result := make([]Family, 0, len(families))
+	for _, f := range families {
+		result = append(result, f)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Skewness returns the skewness of d, or NaN when it is unknown or
+// undefined.
+func (f Family) Skewness(d Distribution, params []float64) float64 {
+	if s, ok := d.(interface{ Skewness() float64 }); ok {
+		return s.Skewness()
+	}
+	if f.Skew != nil {
+		return f.Skew(params)
+	}
+	return math.NaN()
+}
+
+// Entropy returns the differential entropy of a continuous or the Shannon
+// entropy of a discrete distribution, in nats. Families without a closed
+// form are summed over their support or integrated as E[-log f(X)] over
+// the quantiles.
+func (f Family) Entropy(d Distribution) float64 {
+	if e, ok := d.(interface{ Entropy() float64 }); ok {
+		return e.Entropy()
+	}
+
+	if f.Discrete {
+		lo, hi := f.Quantile(d, 1e-12), f.Quantile(d, 1-1e-12)
+		h := 0.0
+		for k := lo; k <= hi; k++ {
+			if p := d.Prob(k); p > 0 {
+				h -= p * math.Log(p)
+			}
+		}
+		return h
+	}
+
+	const steps = 20000
+	h := 0.0
+	for i := 0; i < steps; i++ {
+		x := f.Quantile(d, (float64(i)+0.5)/steps)
+		h -= math.Log(d.Prob(x))
+	}
+	return h / steps
+}
+
+// Quantile returns the smallest x with CDF(x) >= p. Discrete quantiles
+// are found by searching the integers when distuv has no Quantile.
+func (f Family) Quantile(d Distribution, p float64) float64 {
+	if q, ok := d.(interface{ Quantile(p float64) float64 }); ok {
+		return q.Quantile(p)
+	}
+
+	// Bracket the quantile by whole standard deviations around the mean
+	step := math.Max(1, math.Ceil(math.Sqrt(d.Variance())))
+	lo := math.Floor(d.Mean())
+	for d.CDF(lo) >= p {
+		lo -= step
+	}
+	hi := lo + step
+	for d.CDF(hi) < p {
+		hi += step
+	}
+
+	// Bisect, keeping CDF(lo) < p <= CDF(hi)
+	for hi-lo > 1 {
+		mid := math.Floor((lo + hi) / 2)
+		if d.CDF(mid) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
@@ -0,0 +1,164 @@
+package distribution
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"testing"
+)
+
+// near reports whether got is within tol of want, relative to want when
+// want is not small.
+func near(got, want, tol float64) bool {
+	return got == want || math.Abs(got-want) <= tol*math.Max(1, math.Abs(want))
+}
+
+func mustNew(t *testing.T, name string, params ...float64) (Family, Distribution) {
+	t.Helper()
+	f, ok := Lookup(name)
+	if !ok {
+		t.Fatalf("Lookup(%q) found no family", name)
+	}
+	d, err := f.New(params, nil)
+	if err != nil {
+		t.Fatalf("%s%v: %v", name, params, err)
+	}
+	return f, d
+}
+
+func TestFamiliesAreSorted(t *testing.T) {
+	fs := Families()
+	if len(fs) == 0 {
+		t.Fatal("no families are registered")
+	}
+	if !sort.SliceIsSorted(fs, func(i, j int) bool { return fs[i].Name < fs[j].Name }) {
+		t.Error("Families() is not sorted by name")
+	}
+	if _, ok := Lookup("no-such-family"); ok {
+		t.Error("Lookup of an unknown family succeeded")
+	}
+}
+
+// Every family must build from its defaults, and the defaults must satisfy
+// its own parameter bounds.
+func TestDefaults(t *testing.T) {
+	for _, f := range Families() {
+		params := make([]float64, len(f.Params))
+		for i, p := range f.Params {
+			params[i] = p.Default
+			if p.Default < p.Min || p.Default > p.Max || p.Positive && p.Default <= 0 {
+				t.Errorf("%s: default %s = %g is out of bounds", f.Name, p.Name, p.Default)
+			}
+		}
+		d, err := f.New(params, nil)
+		if err != nil {
+			t.Errorf("%s: New(defaults): %v", f.Name, err)
+			continue
+		}
+		if m := d.Mean(); math.IsNaN(m) {
+			t.Errorf("%s: the default mean is NaN", f.Name)
+		}
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []float64
+		p      float64
+		want   float64
+	}{
+		// Discrete families without a distuv Quantile are searched
+		{"binomial", []float64{10, 0.5}, 0.5, 5},
+		{"binomial", []float64{10, 0.5}, 1.0 / 1024, 0},
+		{"binomial", []float64{10, 0.5}, 1, 10},
+		{"poisson", []float64{4}, 0.5, 4},
+		{"poisson", []float64{4}, 0.95, 8},
+		{"normal", []float64{0, 1}, 0.975, 1.959964},
+		{"exponential", []float64{2}, 0.5, math.Ln2 / 2},
+	}
+	for _, tt := range tests {
+		f, d := mustNew(t, tt.name, tt.params...)
+		if got := f.Quantile(d, tt.p); !near(got, tt.want, 1e-6) {
+			t.Errorf("%s%v: Quantile(%g) = %g, want %g", tt.name, tt.params, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestSkewness(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []float64
+		want   float64
+	}{
+		{"normal", []float64{3, 2}, 0},
+		{"exponential", []float64{5}, 2},
+		{"gamma", []float64{4, 1}, 1},
+		{"chi-squared", []float64{2}, 2},
+		{"pareto", []float64{1, 3}, math.NaN()},
+		{"students-t", []float64{3, 0, 1}, math.NaN()},
+	}
+	for _, tt := range tests {
+		f, d := mustNew(t, tt.name, tt.params...)
+		got := f.Skewness(d, tt.params)
+		if math.IsNaN(tt.want) != math.IsNaN(got) || !math.IsNaN(got) && !near(got, tt.want, 1e-12) {
+			t.Errorf("%s%v: Skewness = %g, want %g", tt.name, tt.params, got, tt.want)
+		}
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []float64
+		want   float64
+		tol    float64
+	}{
+		{"normal", []float64{0, 1}, 0.5 * math.Log(2*math.Pi*math.E), 1e-12},
+		// Summed over the support
+		{"binomial", []float64{1, 0.5}, math.Ln2, 1e-12},
+		{"binomial", []float64{2, 0.5}, 1.5 * math.Ln2, 1e-12},
+		// Integrated over the quantiles: the logistic entropy is ln s + 2
+		{"logistic", []float64{0, 1}, 2, 1e-3},
+		{"logistic", []float64{5, 3}, math.Log(3) + 2, 1e-3},
+	}
+	for _, tt := range tests {
+		f, d := mustNew(t, tt.name, tt.params...)
+		if got := f.Entropy(d); !near(got, tt.want, tt.tol) {
+			t.Errorf("%s%v: Entropy = %g, want %g", tt.name, tt.params, got, tt.want)
+		}
+	}
+}
+
+func TestParamErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []float64
+		param  string
+	}{
+		{"binomial", []float64{10, 0}, "p"},
+		{"binomial", []float64{10, 1}, "p"},
+		{"uniform", []float64{1, 1}, "max"},
+		{"uniform", []float64{2, 1}, "max"},
+	}
+	for _, tt := range tests {
+		f, _ := Lookup(tt.name)
+		_, err := f.New(tt.params, nil)
+		var pe *ParamError
+		if !errors.As(err, &pe) || pe.Param != tt.param {
+			t.Errorf("%s%v: error %v, want a ParamError for %s", tt.name, tt.params, err, tt.param)
+		}
+	}
+}
+
+// The logistic wrapper corrects distuv's LogProb, so it must agree with
+// the log of the density.
+func TestLogisticLogProb(t *testing.T) {
+	_, d := mustNew(t, "logistic", 2, 0.5)
+	for _, x := range []float64{-3, 0, 2, 2.5, 40} {
+		lp := d.(interface{ LogProb(float64) float64 }).LogProb(x)
+		if want := math.Log(d.Prob(x)); !near(lp, want, 1e-9) {
+			t.Errorf("LogProb(%g) = %g, want %g", x, lp, want)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package distribution
+
+import (
+	"math"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// maxParam bounds the parameters of the built in families.
+const maxParam = 1e6
+
+func init() {
+	Register(Family{
+		Name:  "normal",
+		Title: "Normal",
+		Params: []Param{
+			{Name: "mu", Default: 0, Min: -maxParam, Max: maxParam},
+			{Name: "sigma", Default: 1, Min: 0, Max: maxParam, Positive: true},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			return distuv.Normal{Mu: p[0], Sigma: p[1], Src: src}, nil
+		},
+		Skew: func([]float64) float64 { return 0 },
+		MLE: func(x []float64) ([]float64, error) {
+			mean, variance := moments(x)
+			return []float64{mean, math.Sqrt(variance)}, nil
+		},
+	})
+
+	Register(Family{
+		Name:     "binomial",
+		Title:    "Binomial",
+		Discrete: true,
+		Params: []Param{
+			{Name: "n", Default: 10, Min: 1, Max: 10000, Integer: true},
+			{Name: "p", Default: 0.5, Min: 0, Max: 1},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			// distuv gives NaN masses for a certain outcome
+			if p[1] == 0 || p[1] == 1 {
+				return nil, &ParamError{Param: "p", Message: "must be strictly between 0 and 1"}
+			}
+			return distuv.Binomial{N: p[0], P: p[1], Src: src}, nil
+		},
+	})
+
+	Register(Family{
+		Name:     "poisson",
+		Title:    "Poisson",
+		Discrete: true,
+		Params: []Param{
+			{Name: "lambda", Default: 4, Min: 0, Max: maxParam, Positive: true},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			return distuv.Poisson{Lambda: p[0], Src: src}, nil
+		},
+		MLE: func(x []float64) ([]float64, error) {
+			for _, v := range x {
+				if v < 0 || v != math.Trunc(v) {
+					return nil, ErrSupport
+				}
+			}
+			mean, _ := moments(x)
+			return []float64{mean}, nil
+		},
+	})
+
+	Register(Family{
+		Name:     "bernoulli",
+		Title:    "Bernoulli",
+		Discrete: true,
+		Params: []Param{
+			{Name: "p", Default: 0.3, Min: 0, Max: 1},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			return distuv.Bernoulli{P: p[0], Src: src}, nil
+		},
+		MLE: func(x []float64) ([]float64, error) {
+			for _, v := range x {
+				if v != 0 && v != 1 {
+					return nil, ErrSupport
+				}
+			}
+			mean, _ := moments(x)
+			return []float64{mean}, nil
+		},
+	})
+
+	Register(Family{
+		Name:  "exponential",
+		Title: "Exponential",
+		Params: []Param{
+			{Name: "rate", Default: 1, Min: 0, Max: maxParam, Positive: true},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			return distuv.Exponential{Rate: p[0], Src: src}, nil
+		},
+		Skew: func([]float64) float64 { return 2 },
+		MLE: func(x []float64) ([]float64, error) {
+			if floats.Min(x) < 0 {
+				return nil, ErrSupport
+			}
+			mean, _ := moments(x)
+			return []float64{1 / mean}, nil
+		},
+	})
+
+	Register(Family{
+		Name:  "gamma",
+		Title: "Gamma",
+		Params: []Param{
+			{Name: "alpha", Default: 2, Min: 0, Max: maxParam, Positive: true},
+			{Name: "beta", Default: 1, Min: 0, Max: maxParam, Positive: true},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			return distuv.Gamma{Alpha: p[0], Beta: p[1], Src: src}, nil
+		},
+		Skew: func(p []float64) float64 { return 2 / math.Sqrt(p[0]) },
+		Start: func(x []float64) []float64 {
+			mean, variance := moments(x)
+			return []float64{mean * mean / variance, mean / variance}
+		},
+	})
+
+	Register(Family{
+		Name:  "beta",
+		Title: "Beta",
+		Params: []Param{
+			{Name: "alpha", Default: 2, Min: 0, Max: maxParam, Positive: true},
+			{Name: "beta", Default: 5, Min: 0, Max: maxParam, Positive: true},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			return distuv.Beta{Alpha: p[0], Beta: p[1], Src: src}, nil
+		},
+		Skew: func(p []float64) float64 {
+			a, b := p[0], p[1]
+			return 2 * (b - a) * math.Sqrt(a+b+1) / ((a + b + 2) * math.Sqrt(a*b))
+		},
+		Start: func(x []float64) []float64 {
+			mean, variance := moments(x)
+			c := mean*(1-mean)/variance - 1
+			return []float64{mean * c, (1 - mean) * c}
+		},
+	})
+
+	Register(Family{
+		Name:  "students-t",
+		Title: "Student's t",
+		Params: []Param{
+			{Name: "nu", Default: 5, Min: 0, Max: maxParam, Positive: true},
+			{Name: "mu", Default: 0, Min: -maxParam, Max: maxParam},
+			{Name: "sigma", Default: 1, Min: 0, Max: maxParam, Positive: true},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			return distuv.StudentsT{Nu: p[0], Mu: p[1], Sigma: p[2], Src: src}, nil
+		},
+		Skew: func(p []float64) float64 {
+			if p[0] <= 3 {
+				return math.NaN()
+			}
+			return 0
+		},
+		Start: func(x []float64) []float64 {
+			// The variance of t with 5 degrees of freedom is 5/3 σ²
+			_, variance := moments(x)
+			return []float64{5, median(x), math.Sqrt(variance * 3 / 5)}
+		},
+	})
+
+	Register(Family{
+		Name:  "chi-squared",
+		Title: "Chi-squared",
+		Params: []Param{
+			{Name: "k", Default: 3, Min: 0, Max: maxParam, Positive: true},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			return distuv.ChiSquared{K: p[0], Src: src}, nil
+		},
+		Skew: func(p []float64) float64 { return math.Sqrt(8 / p[0]) },
+		Start: func(x []float64) []float64 {
+			mean, _ := moments(x)
+			return []float64{mean}
+		},
+	})
+
+	Register(Family{
+		Name:  "f",
+		Title: "F",
+		Params: []Param{
+			{Name: "d1", Default: 5, Min: 0, Max: maxParam, Positive: true},
+			{Name: "d2", Default: 10, Min: 0, Max: maxParam, Positive: true},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			return distuv.F{D1: p[0], D2: p[1], Src: src}, nil
+		},
+		Start: func(x []float64) []float64 {
+			// The mean d2/(d2-2) only depends on d2, and only exists above 2
+			mean, _ := moments(x)
+			d2 := 10.0
+			if mean > 1 {
+				d2 = 2 * mean / (mean - 1)
+			}
+			return []float64{5, d2}
+		},
+	})
+
+	Register(Family{
+		Name:  "uniform",
+		Title: "Uniform",
+		Params: []Param{
+			{Name: "min", Default: 0, Min: -maxParam, Max: maxParam},
+			{Name: "max", Default: 1, Min: -maxParam, Max: maxParam},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			if p[0] >= p[1] {
+				return nil, &ParamError{Param: "max", Message: "must be greater than min"}
+			}
+			return distuv.Uniform{Min: p[0], Max: p[1], Src: src}, nil
+		},
+		Skew: func([]float64) float64 { return 0 },
+		MLE: func(x []float64) ([]float64, error) {
+			return []float64{floats.Min(x), floats.Max(x)}, nil
+		},
+	})
+
+	Register(Family{
+		Name:  "lognormal",
+		Title: "Log-normal",
+		Params: []Param{
+			{Name: "mu", Default: 0, Min: -100, Max: 100},
+			{Name: "sigma", Default: 0.5, Min: 0, Max: 10, Positive: true},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			return distuv.LogNormal{Mu: p[0], Sigma: p[1], Src: src}, nil
+		},
+		MLE: func(x []float64) ([]float64, error) {
+			if floats.Min(x) <= 0 {
+				return nil, ErrSupport
+			}
+			logs := make([]float64, len(x))
+			for i, v := range x {
+				logs[i] = math.Log(v)
+			}
+			mean, variance := moments(logs)
+			return []float64{mean, math.Sqrt(variance)}, nil
+		},
+	})
+
+	Register(Family{
+		Name:  "weibull",
+		Title: "Weibull",
+		Params: []Param{
+			{Name: "k", Default: 1.5, Min: 0, Max: maxParam, Positive: true},
+			{Name: "lambda", Default: 1, Min: 0, Max: maxParam, Positive: true},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			return distuv.Weibull{K: p[0], Lambda: p[1], Src: src}, nil
+		},
+		Start: func(x []float64) []float64 {
+			// Approximately, k is the coefficient of variation to the
+			// power -1.086
+			mean, variance := moments(x)
+			k := math.Pow(math.Sqrt(variance)/mean, -1.086)
+			return []float64{k, mean / math.Gamma(1+1/k)}
+		},
+	})
+
+	Register(Family{
+		Name:  "laplace",
+		Title: "Laplace",
+		Params: []Param{
+			{Name: "mu", Default: 0, Min: -maxParam, Max: maxParam},
+			{Name: "scale", Default: 1, Min: 0, Max: maxParam, Positive: true},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			return distuv.Laplace{Mu: p[0], Scale: p[1], Src: src}, nil
+		},
+		Skew: func([]float64) float64 { return 0 },
+		MLE: func(x []float64) ([]float64, error) {
+			mu := median(x)
+			scale := 0.0
+			for _, v := range x {
+				scale += math.Abs(v - mu)
+			}
+			return []float64{mu, scale / float64(len(x))}, nil
+		},
+	})
+
+	Register(Family{
+		Name:  "logistic",
+		Title: "Logistic",
+		Params: []Param{
+			{Name: "mu", Default: 0, Min: -maxParam, Max: maxParam},
+			{Name: "s", Default: 1, Min: 0, Max: maxParam, Positive: true},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			return newLogistic(p[0], p[1], src), nil
+		},
+		Start: func(x []float64) []float64 {
+			// The variance is s²π²/3
+			mean, variance := moments(x)
+			return []float64{mean, math.Sqrt(3*variance) / math.Pi}
+		},
+	})
+
+	Register(Family{
+		Name:  "pareto",
+		Title: "Pareto",
+		Params: []Param{
+			{Name: "xm", Default: 1, Min: 0, Max: maxParam, Positive: true},
+			{Name: "alpha", Default: 3, Min: 0, Max: maxParam, Positive: true},
+		},
+		New: func(p []float64, src rand.Source) (Distribution, error) {
+			return distuv.Pareto{Xm: p[0], Alpha: p[1], Src: src}, nil
+		},
+		Skew: func(p []float64) float64 {
+			a := p[1]
+			if a <= 3 {
+				return math.NaN()
+			}
+			return 2 * (1 + a) / (a - 3) * math.Sqrt((a-2)/a)
+		},
+		MLE: func(x []float64) ([]float64, error) {
+			xm := floats.Min(x)
+			if xm <= 0 {
+				return nil, ErrSupport
+			}
+			sum := 0.0
+			for _, v := range x {
+				sum += math.Log(v / xm)
+			}
+			return []float64{xm, float64(len(x)) / sum}, nil
+		},
+	})
+}
+
+// logistic adds inverse transform sampling to distuv.Logistic, which has
+// no Rand method, and corrects its LogProb.
+type logistic struct {
+	distuv.Logistic
+	rnd *rand.Rand
+}
+
+func newLogistic(mu, s float64, src rand.Source) logistic {
+	l := logistic{Logistic: distuv.Logistic{Mu: mu, S: s}}
+	if src != nil {
+		l.rnd = rand.New(src)
+	}
+	return l
+}
+
+// LogProb returns the log density at x. distuv.Logistic.LogProb leaves out
+// Mu and S.
+func (l logistic) LogProb(x float64) float64 {
+	// The density is symmetric about Mu, and exp(-|z|) cannot overflow
+	z := math.Abs(x-l.Mu) / l.S
+	return -z - math.Log(l.S) - 2*math.Log1p(math.Exp(-z))
+}
+
+// Rand returns a random sample drawn from the distribution.
+func (l logistic) Rand() float64 {
+	if l.rnd == nil {
+		return l.Quantile(rand.Float64())
+	}
+	return l.Quantile(l.rnd.Float64())
+}
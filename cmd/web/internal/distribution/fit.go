@@ -0,0 +1,190 @@
+package distribution
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// maxEvaluations bounds the likelihood evaluations of a numeric fit.
+const maxEvaluations = 20000
+
+var (
+	// ErrTooFew is returned when a sample has fewer than 2 values.
+	ErrTooFew = errors.New("distribution: too few values")
+	// ErrSupport is returned when a sample has values the family gives no
+	// probability to.
+	ErrSupport = errors.New("distribution: values outside the support of the family")
+	// ErrDegenerate is returned when the likelihood is largest at the edge
+	// of the parameter space, such as for a sample of equal values.
+	ErrDegenerate = errors.New("distribution: the estimates are degenerate")
+	// ErrNoEstimator is returned for families with neither MLE nor Start.
+	ErrNoEstimator = errors.New("distribution: the family cannot be fitted")
+	// ErrNoConvergence is returned when the numeric maximization of the
+	// likelihood does not converge.
+	ErrNoConvergence = errors.New("distribution: the likelihood did not converge")
+)
+
+// Fit is a distribution fitted to a sample by maximum likelihood, with the
+// information criteria of the fit. Lower criteria are better; BIC
+// penalizes the parameters more than AIC for samples of 8 or more values.
+type Fit struct {
+	Family        Family
+	Params        []float64
+	Dist          Distribution
+	ClosedForm    bool
+	LogLikelihood float64
+	AIC           float64
+	BIC           float64
+}
+
+// Fittable reports whether the parameters of the family can be estimated.
+func (f Family) Fittable() bool {
+	return f.MLE != nil || f.Start != nil
+}
+
+// Fit returns the distribution of the family that maximizes the likelihood
+// of x: in closed form when the family has MLE, else by the Nelder-Mead
+// method from Start, with positive parameters optimized on the log scale.
+func (f Family) Fit(x []float64) (Fit, error) {
+	if len(x) < 2 {
+		return Fit{}, ErrTooFew
+	}
+	if floats.Min(x) == floats.Max(x) {
+		return Fit{}, ErrDegenerate
+	}
+	var params []float64
+	var err error
+	switch {
+	case f.MLE != nil:
+		params, err = f.MLE(x)
+	case f.Start != nil:
+		params, err = f.maximize(x)
+	default:
+		err = ErrNoEstimator
+	}
+	if err != nil {
+		return Fit{}, err
+	}
+
+	for i, p := range f.Params {
+		if math.IsNaN(params[i]) || math.IsInf(params[i], 0) || p.Positive && params[i] <= 0 {
+			return Fit{}, ErrDegenerate
+		}
+	}
+	d, err := f.New(params, nil)
+	if err != nil {
+		return Fit{}, ErrDegenerate
+	}
+	ll := LogLikelihood(d, x)
+	if math.IsNaN(ll) || math.IsInf(ll, 0) {
+		return Fit{}, ErrSupport
+	}
+
+	k, n := float64(len(params)), float64(len(x))
+	return Fit{
+		Family:        f,
+		Params:        params,
+		Dist:          d,
+		ClosedForm:    f.MLE != nil,
+		LogLikelihood: ll,
+		AIC:           2*k - 2*ll,
+		BIC:           k*math.Log(n) - 2*ll,
+	}, nil
+}
+
+// maximize returns the parameters that maximize the likelihood of x,
+// searching from f.Start.
+func (f Family) maximize(x []float64) ([]float64, error) {
+	// Positive parameters are searched as their logarithms, which keeps
+	// them positive without constraints
+	start := f.Start(x)
+	theta := make([]float64, len(start))
+	for i, p := range f.Params {
+		v := start[i]
+		if p.Positive {
+			if !(v > 0) || math.IsInf(v, 1) {
+				v = 1
+			}
+			v = math.Log(v)
+		}
+		theta[i] = v
+	}
+	params := func(theta []float64) []float64 {
+		p := make([]float64, len(theta))
+		for i, v := range theta {
+			if f.Params[i].Positive {
+				v = math.Exp(v)
+			}
+			p[i] = v
+		}
+		return p
+	}
+	negative := func(theta []float64) float64 {
+		d, err := f.New(params(theta), nil)
+		if err != nil {
+			return math.Inf(1)
+		}
+		ll := LogLikelihood(d, x)
+		if math.IsNaN(ll) {
+			return math.Inf(1)
+		}
+		return -ll
+	}
+	if math.IsInf(negative(theta), 1) {
+		return nil, ErrSupport
+	}
+
+	result, err := optimize.Minimize(
+		optimize.Problem{Func: negative},
+		theta,
+		&optimize.Settings{FuncEvaluations: maxEvaluations},
+		&optimize.NelderMead{},
+	)
+	// Minimize reports running out of evaluations in the status only
+	if err != nil || result.Status.Early() {
+		return nil, ErrNoConvergence
+	}
+	return params(result.X), nil
+}
+
+// LogLikelihood returns the sum of the log densities, or log masses, of
+// the values of x under d.
+func LogLikelihood(d Distribution, x []float64) float64 {
+	logProb := func(v float64) float64 { return math.Log(d.Prob(v)) }
+	if l, ok := d.(interface{ LogProb(float64) float64 }); ok {
+		logProb = l.LogProb
+	}
+	ll := 0.0
+	for _, v := range x {
+		ll += logProb(v)
+	}
+	return ll
+}
+
+// moments returns the mean and the biased variance of x, which are the
+// maximum likelihood estimates of the normal.
+func moments(x []float64) (mean, variance float64) {
+	for _, v := range x {
+		mean += v
+	}
+	mean /= float64(len(x))
+	for _, v := range x {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, variance / float64(len(x))
+}
+
+// median returns the median of x.
+func median(x []float64) float64 {
+	sorted := append([]float64(nil), x...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
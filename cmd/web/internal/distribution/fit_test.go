@@ -0,0 +1,112 @@
+package distribution
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+var sample = []float64{0.8, 1.3, 1.9, 2.2, 2.6, 3.1, 3.5, 4.4, 5.2, 7.0}
+
+func TestFitClosedForm(t *testing.T) {
+	tests := []struct {
+		name string
+		x    []float64
+		want []float64
+	}{
+		{"normal", []float64{1, 2, 3, 4}, []float64{2.5, math.Sqrt(1.25)}},
+		{"exponential", []float64{1, 2, 3, 6}, []float64{1.0 / 3}},
+		{"poisson", []float64{0, 2, 3, 3}, []float64{2}},
+		{"bernoulli", []float64{0, 1, 1, 1}, []float64{0.75}},
+		{"uniform", []float64{2, 5, 3}, []float64{2, 5}},
+		{"laplace", []float64{1, 2, 3, 10}, []float64{2.5, 2.5}},
+		{"lognormal", []float64{1, math.E, math.E * math.E}, []float64{1, math.Sqrt(2.0 / 3)}},
+		{"pareto", []float64{1, math.E, math.E}, []float64{1, 1.5}},
+	}
+	for _, tt := range tests {
+		f, _ := Lookup(tt.name)
+		fit, err := f.Fit(tt.x)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !fit.ClosedForm {
+			t.Errorf("%s: the fit is not in closed form", tt.name)
+		}
+		for i := range tt.want {
+			if !near(fit.Params[i], tt.want[i], 1e-12) {
+				t.Errorf("%s: Params = %v, want %v", tt.name, fit.Params, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFitCriteria(t *testing.T) {
+	f, _ := Lookup("normal")
+	fit, err := f.Fit(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ll := LogLikelihood(fit.Dist, sample)
+	if !near(fit.LogLikelihood, ll, 1e-12) || !near(fit.AIC, 4-2*ll, 1e-12) || !near(fit.BIC, 2*math.Log(10)-2*ll, 1e-12) {
+		t.Errorf("log likelihood %g, AIC %g, BIC %g do not follow from %g", fit.LogLikelihood, fit.AIC, fit.BIC, ll)
+	}
+}
+
+// At the maximum likelihood the gamma mean alpha/beta equals the sample
+// mean, and the likelihood is no lower than at the moment estimates the
+// search starts from.
+func TestFitNumeric(t *testing.T) {
+	f, _ := Lookup("gamma")
+	fit, err := f.Fit(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fit.ClosedForm {
+		t.Error("the gamma fit is in closed form")
+	}
+	if mean := 3.2; !near(fit.Dist.Mean(), mean, 1e-4) {
+		t.Errorf("fitted mean = %g, want %g", fit.Dist.Mean(), mean)
+	}
+	start, err := f.New(f.Start(sample), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ll := LogLikelihood(start, sample); fit.LogLikelihood < ll {
+		t.Errorf("log likelihood %g is below the start's %g", fit.LogLikelihood, ll)
+	}
+}
+
+func TestFitErrors(t *testing.T) {
+	unfittable := Family{Name: "unfittable", New: func(p []float64, _ rand.Source) (Distribution, error) {
+		return nil, nil
+	}}
+	lookup := func(name string) Family {
+		f, _ := Lookup(name)
+		return f
+	}
+	tests := []struct {
+		name   string
+		family Family
+		x      []float64
+		want   error
+	}{
+		{"one value", lookup("normal"), []float64{1}, ErrTooFew},
+		{"constant closed form", lookup("normal"), []float64{2, 2, 2}, ErrDegenerate},
+		{"constant numeric", lookup("gamma"), []float64{2, 2, 2}, ErrDegenerate},
+		{"negative exponential", lookup("exponential"), []float64{-1, 2}, ErrSupport},
+		{"fractional poisson", lookup("poisson"), []float64{1, 2.5}, ErrSupport},
+		{"negative gamma", lookup("gamma"), []float64{-1, 2, 3}, ErrSupport},
+		{"no estimator", unfittable, []float64{1, 2}, ErrNoEstimator},
+	}
+	for _, tt := range tests {
+		if _, err := tt.family.Fit(tt.x); err != tt.want {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if unfittable.Fittable() {
+		t.Error("a family without MLE or Start is fittable")
+	}
+}
@@ -0,0 +1,106 @@
+// Package estimation computes confidence intervals for the parameters of a
+// population from a sample.
+package estimation
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ErrTooFew is returned when a sample is too small for the interval.
+var ErrTooFew = errors.New("estimation: too few observations")
+
+// Interval is a confidence interval around a point estimate.
+type Interval struct {
+	Estimate float64
+	Lower    float64
+	Upper    float64
+}
+
+// Contains reports whether v lies in the interval.
+func (i Interval) Contains(v float64) bool {
+	return i.Lower <= v && v <= i.Upper
+}
+
+// MeanZ returns the interval for the mean of a population with the known
+// standard deviation sigma.
+func MeanZ(x []float64, sigma, level float64) (Interval, error) {
+	if len(x) == 0 {
+		return Interval{}, ErrTooFew
+	}
+
+	mean := stat.Mean(x, nil)
+	half := criticalZ(level) * sigma / math.Sqrt(float64(len(x)))
+	return Interval{Estimate: mean, Lower: mean - half, Upper: mean + half}, nil
+}
+
+// MeanT returns the interval for the mean of a normal population with an
+// unknown standard deviation, estimated from the sample.
+func MeanT(x []float64, level float64) (Interval, error) {
+	n := float64(len(x))
+	if n < 2 {
+		return Interval{}, ErrTooFew
+	}
+
+	mean, sd := stat.MeanStdDev(x, nil)
+	t := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: n - 1}.Quantile(1 - (1-level)/2)
+	half := t * sd / math.Sqrt(n)
+	return Interval{Estimate: mean, Lower: mean - half, Upper: mean + half}, nil
+}
+
+// ProportionWald returns the normal approximation interval for a
+// proportion with the given number of successes in n trials. It collapses
+// to a point when every trial or none succeeds.
+func ProportionWald(successes, n int, level float64) (Interval, error) {
+	if n == 0 {
+		return Interval{}, ErrTooFew
+	}
+
+	p := float64(successes) / float64(n)
+	half := criticalZ(level) * math.Sqrt(p*(1-p)/float64(n))
+	return Interval{Estimate: p, Lower: math.Max(0, p-half), Upper: math.Min(1, p+half)}, nil
+}
+
+// ProportionWilson returns the Wilson score interval for a proportion,
+// which keeps its coverage for small samples and extreme proportions.
+func ProportionWilson(successes, n int, level float64) (Interval, error) {
+	if n == 0 {
+		return Interval{}, ErrTooFew
+	}
+
+	p, m := float64(successes)/float64(n), float64(n)
+	z := criticalZ(level)
+	scale := 1 + z*z/m
+	center := (p + z*z/(2*m)) / scale
+	half := z / scale * math.Sqrt(p*(1-p)/m+z*z/(4*m*m))
+	// The interval always contains p, but when no trial or every trial
+	// succeeds rounding can leave the bound at p just past it
+	return Interval{Estimate: p, Lower: math.Min(p, center-half), Upper: math.Max(p, center+half)}, nil
+}
+
+// Variance returns the chi-square interval for the variance of a normal
+// population.
+func Variance(x []float64, level float64) (Interval, error) {
+	n := float64(len(x))
+	if n < 2 {
+		return Interval{}, ErrTooFew
+	}
+
+	v := stat.Variance(x, nil)
+	chi := distuv.ChiSquared{K: n - 1}
+	alpha := 1 - level
+	return Interval{
+		Estimate: v,
+		Lower:    (n - 1) * v / chi.Quantile(1-alpha/2),
+		Upper:    (n - 1) * v / chi.Quantile(alpha/2),
+	}, nil
+}
+
+// criticalZ returns the standard normal quantile that leaves (1-level)/2
+// in the upper tail.
+func criticalZ(level float64) float64 {
+	return distuv.UnitNormal.Quantile(1 - (1-level)/2)
+}
@@ -0,0 +1,76 @@
+package estimation
+
+import (
+	"math"
+	"testing"
+)
+
+// Student's sleep data for the first drug, as shipped with R.
+var sleep1 = []float64{0.7, -1.6, -0.2, -1.2, -0.1, 3.4, 3.7, 0.8, 0.0, 2.0}
+
+func near(got, want, tol float64) bool {
+	return got == want || math.Abs(got-want) <= tol*math.Max(1, math.Abs(want))
+}
+
+func TestIntervals(t *testing.T) {
+	// With no successes the Wilson interval is [0, z²/(n+z²)]
+	z := 1.959964
+	tests := []struct {
+		name     string
+		interval func() (Interval, error)
+		want     Interval
+	}{
+		{"mean z", func() (Interval, error) { return MeanZ([]float64{1, 2, 3}, 1, 0.95) }, Interval{2, 2 - z/math.Sqrt(3), 2 + z/math.Sqrt(3)}},
+		// R's t.test(sleep1)
+		{"mean t", func() (Interval, error) { return MeanT(sleep1, 0.95) }, Interval{0.75, -0.5297804, 2.0297804}},
+		{"wald", func() (Interval, error) { return ProportionWald(5, 10, 0.95) }, Interval{0.5, 0.5 - z*math.Sqrt(0.025), 0.5 + z*math.Sqrt(0.025)}},
+		{"wald none", func() (Interval, error) { return ProportionWald(0, 10, 0.95) }, Interval{0, 0, 0}},
+		{"wald all", func() (Interval, error) { return ProportionWald(10, 10, 0.95) }, Interval{1, 1, 1}},
+		{"wald clipped", func() (Interval, error) { return ProportionWald(1, 10, 0.95) }, Interval{0.1, 0, 0.1 + z*math.Sqrt(0.009)}},
+		{"wilson none", func() (Interval, error) { return ProportionWilson(0, 10, 0.95) }, Interval{0, 0, z * z / (10 + z*z)}},
+		{"wilson all", func() (Interval, error) { return ProportionWilson(10, 10, 0.95) }, Interval{1, 10 / (10 + z*z), 1}},
+		// (n-1)s² divided by the chi-square quantiles with 9 degrees of
+		// freedom, 19.02277 and 2.700389
+		{"variance", func() (Interval, error) { return Variance(sleep1, 0.95) }, Interval{3.200556, 9 * 3.200556 / 19.02277, 9 * 3.200556 / 2.700389}},
+	}
+	for _, tt := range tests {
+		got, err := tt.interval()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !near(got.Estimate, tt.want.Estimate, 1e-6) || !near(got.Lower, tt.want.Lower, 1e-6) || !near(got.Upper, tt.want.Upper, 1e-6) {
+			t.Errorf("%s = %+v, want %+v", tt.name, got, tt.want)
+		}
+		if !got.Contains(got.Estimate) {
+			t.Errorf("%s = %+v does not contain its estimate", tt.name, got)
+		}
+	}
+}
+
+func TestIntervalErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval func() (Interval, error)
+	}{
+		{"mean z", func() (Interval, error) { return MeanZ(nil, 1, 0.95) }},
+		{"mean t", func() (Interval, error) { return MeanT([]float64{1}, 0.95) }},
+		{"wald", func() (Interval, error) { return ProportionWald(0, 0, 0.95) }},
+		{"wilson", func() (Interval, error) { return ProportionWilson(0, 0, 0.95) }},
+		{"variance", func() (Interval, error) { return Variance([]float64{1}, 0.95) }},
+	}
+	for _, tt := range tests {
+		if _, err := tt.interval(); err != ErrTooFew {
+			t.Errorf("%s: error %v, want %v", tt.name, err, ErrTooFew)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	i := Interval{Estimate: 1, Lower: 0, Upper: 2}
+	for v, want := range map[float64]bool{-0.1: false, 0: true, 1.5: true, 2: true, 2.1: false} {
+		if got := i.Contains(v); got != want {
+			t.Errorf("Contains(%g) = %t, want %t", v, got, want)
+		}
+	}
+}
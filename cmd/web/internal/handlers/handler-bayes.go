@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/text"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// maxTestResults limits the tests applied in turn by the sequential mode.
+const maxTestResults = 50
+
+// bayesBreakdown splits the population by condition and test result with
+// the law of total probability.
+func bayesBreakdown(prior, sensitivity, specificity float64) models.BayesBreakdown {
+	b := models.BayesBreakdown{
+		TruePositive:  sensitivity * prior,
+		FalseNegative: (1 - sensitivity) * prior,
+		FalsePositive: (1 - specificity) * (1 - prior),
+		TrueNegative:  specificity * (1 - prior),
+	}
+	b.Positive = b.TruePositive + b.FalsePositive
+	b.Negative = b.FalseNegative + b.TrueNegative
+	return b
+}
+
+// bayesUpdate applies one test result to prior and returns the probability
+// of that result and the posterior probability of the condition. The
+// posterior is NaN when the result has probability zero.
+func bayesUpdate(prior, sensitivity, specificity float64, positive bool) (float64, float64) {
+	b := bayesBreakdown(prior, sensitivity, specificity)
+	if positive {
+		return b.Positive, b.TruePositive / b.Positive
+	}
+	return b.Negative, b.FalseNegative / b.Negative
+}
+
+// parseTestResults reads a comma separated list of positive and negative
+// test results, also accepted as + and -. In a query string the + must be
+// escaped as %2B, since an unescaped + decodes to a space.
+func parseTestResults(raw string) ([]bool, bool) {
+	if raw == "" {
+		return nil, true
+	}
+
+	var results []bool
+	for _, r := range strings.Split(raw, ",") {
+		switch strings.TrimSpace(r) {
+		case "positive", "+":
+			results = append(results, true)
+		case "negative", "-":
+			results = append(results, false)
+		default:
+			return nil, false
+		}
+	}
+	return results, true
+}
+
+func Bayes(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "bayes")
+}
+
+func bayesTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	input := q.Enum("input", "rates", "rates", "table")
+
+	// The drug test example: 0.3% of people use the drug and the test is
+	// right for 99% of users and non-users
+	prior := q.Float("prior", 0.003, 0, 1)
+	sensitivity := q.Float("sensitivity", 0.99, 0, 1)
+	specificity := q.Float("specificity", 0.99, 0, 1)
+
+	// A contingency table of condition against test result
+	tp := q.Int("tp", 0, 0, maxSampleSize)
+	fn := q.Int("fn", 0, 0, maxSampleSize)
+	fp := q.Int("fp", 0, 0, maxSampleSize)
+	tn := q.Int("tn", 0, 0, maxSampleSize)
+	if input == "table" {
+		q.Check(tp+fn > 0, "tp", "the table needs at least one case with the condition")
+		q.Check(fp+tn > 0, "tn", "the table needs at least one case without the condition")
+	}
+
+	results, ok := parseTestResults(q.String("results", ""))
+	q.Check(ok, "results", "must be a comma separated list of positive and negative")
+	q.Check(len(results) <= maxTestResults, "results", fmt.Sprintf("must list at most %d results", maxTestResults))
+	if !q.Valid() {
+		return &models.BayesResponse{}, nil, nil
+	}
+
+	if input == "table" {
+		prior = float64(tp+fn) / float64(tp+fn+fp+tn)
+		sensitivity = float64(tp) / float64(tp+fn)
+		specificity = float64(tn) / float64(fp+tn)
+	}
+
+	breakdown := bayesBreakdown(prior, sensitivity, specificity)
+	svgResponse := &models.BayesResponse{
+		Input:             input,
+		Prior:             prior,
+		Sensitivity:       sensitivity,
+		Specificity:       specificity,
+		Breakdown:         breakdown,
+		PosteriorPositive: finite(breakdown.TruePositive / breakdown.Positive),
+		PosteriorNegative: finite(breakdown.FalseNegative / breakdown.Negative),
+	}
+
+	// Every posterior is the prior of the next test, so an impossible
+	// result leaves the rest of the sequence undefined
+	current := prior
+	for i, positive := range results {
+		evidence, posterior := bayesUpdate(current, sensitivity, specificity, positive)
+		result := "negative"
+		if positive {
+			result = "positive"
+		}
+		if evidence == 0 {
+			q.Check(false, "results", fmt.Sprintf("result %d is %s, which has probability zero", i+1, result))
+			return &models.BayesResponse{}, nil, nil
+		}
+		svgResponse.Updates = append(svgResponse.Updates, models.BayesUpdate{
+			Result:    result,
+			Prior:     current,
+			Evidence:  evidence,
+			Posterior: posterior,
+		})
+		current = posterior
+	}
+
+	tree, err := bayesTree(prior, sensitivity, specificity, breakdown, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	area, err := bayesArea(prior, sensitivity, specificity, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	plots := []namedPlot{{"tree", tree}, {"area", area}}
+	if len(svgResponse.Updates) > 0 {
+		updates, err := bayesUpdates(svgResponse.Updates, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		plots = append(plots, namedPlot{"updates", updates})
+	}
+
+	return svgResponse, plots, nil
+}
+
+// bayesTree draws the probability tree: the condition branches first, then
+// the test result, with the joint probability at every leaf.
+func bayesTree(prior, sensitivity, specificity float64, b models.BayesBreakdown, opts plotting.Options) (*plot.Plot, error) {
+	p := plot.New()
+	p.Title.Text = "Probability tree"
+	p.HideAxes()
+	p.X.Min, p.X.Max = -0.3, 2.6
+	p.Y.Min, p.Y.Max = -0.1, 1.1
+
+	type edge struct {
+		from, to plotter.XY
+		label    string
+	}
+	root := plotter.XY{X: 0, Y: 0.5}
+	condition := plotter.XY{X: 1, Y: 0.8}
+	healthy := plotter.XY{X: 1, Y: 0.2}
+	edges := []edge{
+		{root, condition, fmt.Sprintf("P(A) = %.4g", prior)},
+		{root, healthy, fmt.Sprintf("P(¬A) = %.4g", 1-prior)},
+		{condition, plotter.XY{X: 2, Y: 0.95}, fmt.Sprintf("P(+|A) = %.4g", sensitivity)},
+		{condition, plotter.XY{X: 2, Y: 0.65}, fmt.Sprintf("P(-|A) = %.4g", 1-sensitivity)},
+		{healthy, plotter.XY{X: 2, Y: 0.35}, fmt.Sprintf("P(+|¬A) = %.4g", 1-specificity)},
+		{healthy, plotter.XY{X: 2, Y: 0.05}, fmt.Sprintf("P(-|¬A) = %.4g", specificity)},
+	}
+
+	labels := plotter.XYLabels{}
+	for _, e := range edges {
+		line, err := plotter.NewLine(plotter.XYs{e.from, e.to})
+		if err != nil {
+			return nil, err
+		}
+		line.Color = opts.Theme.Primary
+		line.Width = vg.Points(1.5)
+		p.Add(line)
+
+		labels.XYs = append(labels.XYs, plotter.XY{X: (e.from.X + e.to.X) / 2, Y: (e.from.Y+e.to.Y)/2 + 0.04})
+		labels.Labels = append(labels.Labels, e.label)
+	}
+
+	leaves := []struct {
+		y     float64
+		label string
+	}{
+		{0.95, fmt.Sprintf("A, +: %.4g", b.TruePositive)},
+		{0.65, fmt.Sprintf("A, -: %.4g", b.FalseNegative)},
+		{0.35, fmt.Sprintf("¬A, +: %.4g", b.FalsePositive)},
+		{0.05, fmt.Sprintf("¬A, -: %.4g", b.TrueNegative)},
+	}
+	for _, leaf := range leaves {
+		labels.XYs = append(labels.XYs, plotter.XY{X: 2.3, Y: leaf.y})
+		labels.Labels = append(labels.Labels, leaf.label)
+	}
+
+	l, err := plotter.NewLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+	for i := range l.TextStyle {
+		l.TextStyle[i].Color = opts.Theme.Foreground
+		l.TextStyle[i].XAlign = draw.XCenter
+		l.TextStyle[i].YAlign = text.YCenter
+	}
+	p.Add(l)
+
+	return p, nil
+}
+
+// bayesArea draws the unit square of the population: the width of each
+// column is the probability of the condition and the shaded heights are
+// the positive results, so the posterior is the share of shaded area that
+// lies in the condition column.
+func bayesArea(prior, sensitivity, specificity float64, opts plotting.Options) (*plot.Plot, error) {
+	p := plot.New()
+	b := bayesBreakdown(prior, sensitivity, specificity)
+	p.Title.Text = "Population by condition and test result"
+	if b.Positive > 0 {
+		p.Title.Text += fmt.Sprintf(", P(A|+) = %.4g", b.TruePositive/b.Positive)
+	}
+	p.X.Label.Text = "P(A)"
+	p.Y.Label.Text = "P(+ | column)"
+	p.X.Min, p.X.Max = 0, 1
+	p.Y.Min, p.Y.Max = 0, 1
+
+	rect := func(x0, x1, y0, y1 float64) plotter.XYs {
+		return plotter.XYs{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}}
+	}
+
+	background, err := plotter.NewPolygon(rect(0, 1, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	background.Color = plotting.Translucent(opts.Theme.Primary, 0.15)
+	background.LineStyle.Color = opts.Theme.Primary
+
+	truePositive, err := plotter.NewPolygon(rect(0, prior, 0, sensitivity))
+	if err != nil {
+		return nil, err
+	}
+	truePositive.Color = opts.Theme.Highlight
+	truePositive.LineStyle.Width = 0
+
+	falsePositive, err := plotter.NewPolygon(rect(prior, 1, 0, 1-specificity))
+	if err != nil {
+		return nil, err
+	}
+	falsePositive.Color = opts.Theme.Primary
+	falsePositive.LineStyle.Width = 0
+
+	p.Add(background, truePositive, falsePositive)
+	p.Legend.Add("A and positive", truePositive)
+	p.Legend.Add("not A and positive", falsePositive)
+	p.Legend.Top = true
+
+	return p, nil
+}
+
+// bayesUpdates draws the posterior after each test of the sequential mode.
+func bayesUpdates(updates []models.BayesUpdate, opts plotting.Options) (*plot.Plot, error) {
+	p := plot.New()
+	p.Title.Text = "Posterior after each test"
+	p.X.Label.Text = "Tests applied"
+	p.Y.Label.Text = "P(A)"
+	p.Y.Min, p.Y.Max = 0, 1
+
+	points := make(plotter.XYs, len(updates)+1)
+	points[0].Y = updates[0].Prior
+	for i, u := range updates {
+		points[i+1].X = float64(i + 1)
+		points[i+1].Y = u.Posterior
+	}
+
+	line, marks, err := plotter.NewLinePoints(points)
+	if err != nil {
+		return nil, err
+	}
+	line.Color = opts.Theme.Primary
+	marks.Color = opts.Theme.Highlight
+
+	p.Add(line, marks)
+	return p, nil
+}
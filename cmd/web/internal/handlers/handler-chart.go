@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"github.com/go-chi/chi/v5"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+)
+
+// namedPlot is one of the charts drawn by a topic.
+type namedPlot struct {
+	name string
+	plot *plot.Plot
+}
+
+// A topic computes one GET statistics route. Compute reads the parameters
+// from q and returns the numeric result with its charts, the first chart
+// being the default one. When q ends up invalid, compute returns an empty
+// response right away; the API documentation relies on that to list the
+// parameters and the response type of every topic.
+type topic struct {
+	compute func(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error)
+	// schema is the versioned name of the response, such as "mean.v1".
+	schema  string
+	summary string
+}
+
+// topics maps the {topic} segment of the statistics routes to its topic.
+// Every registered distribution family is added as "distribution/{name}".
+var topics = map[string]topic{
+	"mean":                   {meanTopic, "mean.v1", "Mean of a normal sample"},
+	"median":                 {medianTopic, "median.v1", "Median of a normal sample"},
+	"quantiles":              {quantilesTopic, "quantiles.v1", "Quantiles, five-number summary and box plot"},
+	"mode":                   {modeTopic, "mode.v1", "Modes of discrete or binned continuous data"},
+	"std-deviation-variance": {stdVarTopic, "std-deviation-variance.v1", "Standard deviation and variance of a normal sample"},
+	"pdf":                    {pdfTopic, "pdf.v1", "Normal probability density function"},
+	"binomial":               {binomialTopic, "binomial.v1", "Binomial probability mass function"},
+	"poisson":                {poissonTopic, "poisson.v1", "Poisson probability mass function"},
+	"covcor":                 {covCorTopic, "covcor.v1", "Covariance and correlation of two samples"},
+	"correlation":            {correlationTopic, "correlation.v1", "Covariance, Pearson, Spearman and Kendall correlation matrices of a table"},
+	"t-test":                 {tTestTopic, "t-test.v1", "One-sample, Welch and paired t-tests"},
+	"chi-square-test":        {chiSquareTestTopic, "chi-square-test.v1", "Chi-square goodness of fit and independence tests"},
+	"anova":                  {anovaTopic, "anova.v1", "One-way analysis of variance"},
+	"normality":              {normalityTopic, "normality.v1", "Shapiro-Wilk, Anderson-Darling, Kolmogorov-Smirnov and Jarque-Bera tests with Q-Q and P-P plots"},
+	"confidence-interval":    {confidenceIntervalTopic, "confidence-interval.v1", "Confidence intervals with a coverage simulation"},
+	"clt":                    {cltTopic, "clt.v1", "Central limit theorem simulation"},
+	"distribution-fit":       {distributionFitTopic, "distribution-fit.v1", "Maximum likelihood fits of distribution families ranked by AIC, BIC or log-likelihood"},
+	"bootstrap":              {bootstrapTopic, "bootstrap.v1", "Bootstrap percentile and BCa confidence intervals"},
+	"permutation-test":       {permutationTestTopic, "permutation-test.v1", "Permutation test of the difference between two groups"},
+	"bayes":                  {bayesTopic, "bayes.v1", "Bayes' theorem for a diagnostic test"},
+	"linear-regression":      {linearRegressionTopic, "linear-regression.v1", "Simple linear regression"},
+	"polynomial-regression":  {polynomialRegressionTopic, "polynomial-regression.v1", "Least squares polynomial regression"},
+	"logistic-regression":    {logisticRegressionTopic, "logistic-regression.v1", "Logistic regression with classification metrics"},
+	"time-series":            {timeSeriesTopic, "time-series.v1", "Moving averages, autocorrelation and seasonal decomposition of a time series"},
+}
+
+// serveTopic sends the numeric result of the named topic as JSON, with links
+// to its charts. The links carry the seed that was used, so the images show
+// the same sample as the numbers. With a format parameter the chart itself
+// is sent instead.
+func serveTopic(w http.ResponseWriter, r *http.Request, name string) {
+	q := helpers.NewQuery(r)
+	opts := chartOptions(q)
+	t := topics[name]
+	result, plots, err := t.compute(q, opts)
+	if !q.Valid() {
+		helpers.InvalidQuery(w, q.Errors)
+		return
+	}
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	if q.Has("format") {
+		renderChart(w, r, q, name, plots, opts)
+		return
+	}
+
+	meta := result.Metadata()
+	meta.Schema = t.schema
+	meta.Charts = make(map[string]string, len(plots))
+	for _, np := range plots {
+		values := q.Values()
+		values.Set("chart", np.name)
+		meta.Charts[np.name] = fmt.Sprintf("/statistics/%s/chart.%s?%s", name, opts.Format, values.Encode())
+	}
+
+	writeJSON(w, result)
+}
+
+// Chart streams one chart of a topic as an image, so it can be used directly
+// as an <img> source and cached by the browser.
+func Chart(w http.ResponseWriter, r *http.Request) {
+	serveChart(w, r, chi.URLParam(r, "topic"))
+}
+
+// serveChart sends one chart of the named topic.
+func serveChart(w http.ResponseWriter, r *http.Request, name string) {
+	t, ok := topics[name]
+	if !ok {
+		helpers.ErrorJSON(w, http.StatusNotFound, fmt.Sprintf("unknown topic %q", name), nil)
+		return
+	}
+
+	format := plotting.Format(chi.URLParam(r, "ext"))
+	if !validFormat(format) {
+		helpers.ErrorJSON(w, http.StatusNotFound, fmt.Sprintf("unknown chart format %q", format), nil)
+		return
+	}
+
+	q := helpers.NewQuery(r)
+	opts := chartOptions(q)
+	opts.Format = format
+	_, plots, err := t.compute(q, opts)
+	if !q.Valid() {
+		helpers.InvalidQuery(w, q.Errors)
+		return
+	}
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	renderChart(w, r, q, name, plots, opts)
+}
+
+// renderChart sends the chart picked by the chart parameter, or the first
+// chart of the topic when it is missing.
+func renderChart(w http.ResponseWriter, r *http.Request, q *helpers.Query, name string, plots []namedPlot, opts plotting.Options) {
+	names := make([]string, len(plots))
+	for i, np := range plots {
+		names[i] = np.name
+	}
+
+	selected := q.Enum("chart", names[0], names...)
+	if !q.Valid() {
+		helpers.InvalidQuery(w, q.Errors)
+		return
+	}
+
+	for _, np := range plots {
+		if np.name != selected {
+			continue
+		}
+
+		img, err := plotting.Render(np.plot, opts)
+		if err != nil {
+			helpers.ServerError(w, err)
+			return
+		}
+
+		writeChart(w, r, q, img, opts.Format, strings.ReplaceAll(name, "/", "-")+"-"+np.name)
+		return
+	}
+}
+
+// writeChart sends a rendered chart with the Content-Type of its format.
+// Charts from a seeded or deterministic query never change, so they may be
+// cached; the others are revalidated with their ETag.
+func writeChart(w http.ResponseWriter, r *http.Request, q *helpers.Query, img []byte, format plotting.Format, filename string) {
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(img))
+
+	w.Header().Set("ETag", etag)
+	if q.Reproducible() {
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	if match := r.Header.Get("If-None-Match"); match == "*" || strings.Contains(match, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename+"."+string(format)))
+	w.Write(img)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	jsonResponse, err := json.Marshal(v)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonResponse)
+}
+
+// chartOptions reads the size, resolution, theme and format of the rendered
+// charts.
+func chartOptions(q *helpers.Query) plotting.Options {
+	opts := plotting.DefaultOptions()
+	opts.Width = vg.Points(float64(q.Int("width", 800, 100, 1600)))
+	opts.Height = vg.Points(float64(q.Int("height", 400, 100, 1600)))
+	opts.DPI = q.Int("dpi", opts.DPI, 36, 192)
+	opts.Theme = plotting.Themes[q.Enum("theme", "light", plotting.ThemeNames()...)]
+
+	formats := make([]string, len(plotting.Formats))
+	for i, f := range plotting.Formats {
+		formats[i] = string(f)
+	}
+	opts.Format = plotting.Format(q.Enum("format", string(plotting.PNG), formats...))
+
+	return opts
+}
+
+func validFormat(f plotting.Format) bool {
+	for _, known := range plotting.Formats {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
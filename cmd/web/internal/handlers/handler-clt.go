@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/distribution"
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/hypothesis"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"github.com/davidhalasz/gomath/cmd/web/internal/random"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+func CLT(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "clt")
+}
+
+// cltTopic draws many samples of size n from a distribution of the
+// registry and compares the histogram of their means with the normal
+// distribution predicted by the central limit theorem.
+func cltTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	families := distribution.Families()
+	names := make([]string, len(families))
+	for i, f := range families {
+		names[i] = f.Name
+	}
+	source := q.Enum("source", "exponential", names...)
+	f, _ := distribution.Lookup(source)
+	params := distributionParams(q, f, "n", "samples", "bins", "seed")
+
+	n := q.Int("n", 1, 1, maxSampleSize)
+	samples := q.Int("samples", 10000, 100, maxSampleSize)
+	q.Check(n*samples <= maxSampleSize, "samples", fmt.Sprintf("samples times n must be at most %d", maxSampleSize))
+	bins := q.Int("bins", 50, 1, maxBins)
+	seed := q.Seed()
+
+	localRand := random.New(seed)
+	dist, err := newDistribution(q, f, params, localRand)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dist != nil {
+		v := dist.Variance()
+		q.Check(!math.IsNaN(v) && !math.IsInf(v, 0), "source", "has no finite variance for these parameters, so the central limit theorem does not apply")
+		q.Check(v != 0, "source", "is constant for these parameters")
+	}
+	if !q.Valid() {
+		return &models.CLTResponse{}, nil, nil
+	}
+
+	means := make([]float64, samples)
+	for i := range means {
+		sum := 0.0
+		for j := 0; j < n; j++ {
+			sum += dist.Rand()
+		}
+		means[i] = sum / float64(n)
+	}
+
+	// The means are approximately normal with the mean of the source and
+	// its standard deviation shrunk by the square root of n
+	predicted := distuv.Normal{Mu: dist.Mean(), Sigma: math.Sqrt(dist.Variance() / float64(n))}
+
+	mean, sd := stat.MeanStdDev(means, nil)
+	svgResponse := &models.CLTResponse{
+		Seed:            seed,
+		Source:          source,
+		Params:          make(map[string]float64, len(params)),
+		N:               n,
+		Samples:         samples,
+		Mean:            mean,
+		StdDev:          sd,
+		PredictedMean:   predicted.Mu,
+		PredictedStdDev: predicted.Sigma,
+		KSDistance:      hypothesis.KSDistance(means, predicted.CDF),
+	}
+	for i, p := range f.Params {
+		svgResponse.Params[p.Name] = params[i]
+	}
+
+	// Means of integer valued samples lie on a grid of spacing 1/n, and
+	// get one bin per grid point
+	lattice := 0.0
+	if f.Discrete {
+		lattice = 1 / float64(n)
+	}
+
+	p, err := cltPlot(means, bins, lattice, predicted, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.Title.Text = fmt.Sprintf("Means of %d %s samples of size %d, KS distance %.4f", samples, f.Title, n, svgResponse.KSDistance)
+
+	return svgResponse, []namedPlot{{"means", p}}, nil
+}
+
+// cltPlot draws the density histogram of the sample means under the
+// predicted normal density. A nonzero lattice centers a bin on every
+// multiple of it instead, unless that takes more than maxBins bins.
+func cltPlot(means []float64, bins int, lattice float64, predicted distuv.Normal, opts plotting.Options) (*plot.Plot, error) {
+	p := plot.New()
+	p.X.Label.Text = "Sample mean"
+	p.Y.Label.Text = "Density"
+
+	var histogram *plotter.Histogram
+	var err error
+	lo, hi := floats.Min(means), floats.Max(means)
+	if lattice > 0 && int(math.Round((hi-lo)/lattice))+1 <= maxBins {
+		histogram, err = plotting.NewLatticeHist(means, lattice)
+	} else {
+		histogram, err = plotting.NewHist(means, bins)
+	}
+	if err != nil {
+		return nil, err
+	}
+	histogram.Normalize(1)
+	histogram.FillColor = opts.Theme.Primary
+	p.Add(histogram)
+
+	xMin, xMax, _, _ := histogram.DataRange()
+	xMin = math.Min(xMin, predicted.Mu-4*predicted.Sigma)
+	xMax = math.Max(xMax, predicted.Mu+4*predicted.Sigma)
+
+	curve := make(plotter.XYs, curvePoints)
+	for i := range curve {
+		x := xMin + float64(i)*(xMax-xMin)/float64(curvePoints-1)
+		curve[i] = plotter.XY{X: x, Y: predicted.Prob(x)}
+	}
+	line, err := plotter.NewLine(curve)
+	if err != nil {
+		return nil, err
+	}
+	line.Color = opts.Theme.Highlight
+	line.Width = vg.Points(2)
+	p.Add(line)
+	p.Legend.Add(fmt.Sprintf("N(%.4g, %.4g²)", predicted.Mu, predicted.Sigma), line)
+	p.Legend.Top = true
+
+	p.X.Min, p.X.Max = xMin, xMax
+	return p, nil
+}
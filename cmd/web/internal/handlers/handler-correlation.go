@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/correlation"
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"github.com/davidhalasz/gomath/cmd/web/internal/random"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette/moreland"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/text"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+const (
+	// maxCorrelationRows limits the rows of a table, as Kendall's
+	// coefficient compares every pair of them.
+	maxCorrelationRows = 2000
+	// maxCorrelationColumns limits the columns of a table, so the cells of
+	// the charts stay readable.
+	maxCorrelationColumns = 10
+)
+
+// correlationTitles names the coefficients in chart titles.
+var correlationTitles = map[correlation.Method]string{
+	correlation.Pearson:  "Pearson",
+	correlation.Spearman: "Spearman",
+	correlation.Kendall:  "Kendall",
+}
+
+func Correlation(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "correlation")
+}
+
+// correlationTopic correlates every pair of columns of a table, given as
+// rows of observations. Without a table it uses the page speeds and
+// purchase amounts of the CovCor topic.
+func correlationTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	table := q.FloatRows("table", nil, -maxParam, maxParam)
+	names := strings.Split(q.String("names", ""), ",")
+	n := q.Int("n", 200, 3, maxCorrelationRows)
+	var seed uint64
+	if table == nil {
+		seed = q.Seed()
+	}
+	method := correlation.Method(q.Enum("method", string(correlation.Pearson), correlationMethods()...))
+
+	var columns [][]float64
+	if table != nil {
+		q.Check(len(table) >= 3, "table", "must have at least 3 rows")
+		q.Check(len(table) <= maxCorrelationRows, "table", fmt.Sprintf("must have at most %d rows", maxCorrelationRows))
+		q.Check(len(table[0]) >= 2, "table", "must have at least 2 columns")
+		q.Check(len(table[0]) <= maxCorrelationColumns, "table", fmt.Sprintf("must have at most %d columns", maxCorrelationColumns))
+		for _, row := range table {
+			q.Check(len(row) == len(table[0]), "table", "every row must have the same length")
+		}
+		if q.Valid() {
+			columns = make([][]float64, len(table[0]))
+			for _, row := range table {
+				for j, v := range row {
+					columns[j] = append(columns[j], v)
+				}
+			}
+		}
+	} else if len(q.Errors) == 0 {
+		columns = exampleColumns(n, seed)
+	}
+
+	// Unnamed columns are called x1, x2 and so on, like the columns of a
+	// CSV upload without a header
+	if names[0] == "" {
+		names = make([]string, len(columns))
+		for i := range names {
+			names[i] = fmt.Sprintf("x%d", i+1)
+		}
+		if table == nil {
+			names = []string{"page_speed", "purchase_amount_1", "purchase_amount_2"}
+		}
+	}
+	if columns != nil {
+		q.Check(len(names) == len(columns), "names", "must name every column")
+		seen := make(map[string]bool)
+		for _, name := range names {
+			q.Check(!seen[name], "names", fmt.Sprintf("%q appears more than once", name))
+			seen[name] = true
+		}
+	}
+	if !q.Valid() {
+		return &models.CorrelationResponse{}, nil, nil
+	}
+
+	covariance, err := correlation.Covariance(columns)
+	if err != nil {
+		return &models.CorrelationResponse{}, nil, correlationError(q, err)
+	}
+	matrices := make(map[correlation.Method]correlation.Matrix, len(correlation.Methods))
+	for _, m := range correlation.Methods {
+		if matrices[m], err = correlation.Correlate(columns, m); err != nil {
+			return &models.CorrelationResponse{}, nil, correlationError(q, err)
+		}
+	}
+
+	svgResponse := &models.CorrelationResponse{
+		Seed:       seed,
+		N:          len(columns[0]),
+		Names:      names,
+		Covariance: matrixRows(covariance),
+		Pearson:    correlationMatrix(matrices[correlation.Pearson]),
+		Spearman:   correlationMatrix(matrices[correlation.Spearman]),
+		Kendall:    correlationMatrix(matrices[correlation.Kendall]),
+	}
+
+	heatmap, err := correlationHeatmap(matrices[method], names, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	scatter, err := scatterMatrix(columns, names, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return svgResponse, []namedPlot{{"heatmap", heatmap}, {"scatter-matrix", scatter}}, nil
+}
+
+// exampleColumns returns the page speeds and purchase amounts of the
+// CovCor topic: the first amounts do not depend on the speed, the second
+// fall with it.
+func exampleColumns(n int, seed uint64) [][]float64 {
+	localRand := random.New(seed)
+	columns := make([][]float64, 3)
+	for i := 0; i < n; i++ {
+		pageSpeed := localRand.NormFloat64()*1.0 + 3.0
+		purchase := localRand.NormFloat64()*10.0 + 50.0
+		columns[0] = append(columns[0], pageSpeed)
+		columns[1] = append(columns[1], localRand.NormFloat64()*10.0+50.0)
+		columns[2] = append(columns[2], purchase/pageSpeed)
+	}
+	return columns
+}
+
+// correlationMethods returns the names of the correlation coefficients.
+func correlationMethods() []string {
+	names := make([]string, len(correlation.Methods))
+	for i, m := range correlation.Methods {
+		names[i] = string(m)
+	}
+	return names
+}
+
+// correlationError records an error caused by the table, so it is reported
+// like any invalid parameter. Other errors are returned.
+func correlationError(q *helpers.Query, err error) error {
+	for _, known := range []error{correlation.ErrTooFew, correlation.ErrLength, correlation.ErrConstant} {
+		if errors.Is(err, known) {
+			q.Check(false, "table", strings.TrimPrefix(err.Error(), "correlation: "))
+			return nil
+		}
+	}
+	return err
+}
+
+func correlationMatrix(m correlation.Matrix) models.CorrelationMatrix {
+	return models.CorrelationMatrix{Coefficients: matrixRows(m.Coefficients), PValues: matrixRows(m.P)}
+}
+
+// matrixRows returns the rows of m as slices.
+func matrixRows(m mat.Matrix) [][]float64 {
+	r, c := m.Dims()
+	rows := make([][]float64, r)
+	for i := range rows {
+		rows[i] = make([]float64, c)
+		for j := range rows[i] {
+			rows[i][j] = m.At(i, j)
+		}
+	}
+	return rows
+}
+
+// correlationGrid lays a correlation matrix out as a heat map grid, with
+// the first column at the top like the printed matrix.
+type correlationGrid struct {
+	*mat.SymDense
+}
+
+func (g correlationGrid) Dims() (c, r int) {
+	k := g.SymmetricDim()
+	return k, k
+}
+
+func (g correlationGrid) Z(c, r int) float64 {
+	return g.At(g.SymmetricDim()-1-r, c)
+}
+
+func (g correlationGrid) X(c int) float64 { return float64(c) }
+
+func (g correlationGrid) Y(r int) float64 { return float64(r) }
+
+// correlationHeatmap colors the coefficients from blue at -1 to red at 1
+// and prints them in their cells, starred when they differ from zero at
+// the 5% level.
+func correlationHeatmap(m correlation.Matrix, names []string, opts plotting.Options) (*plot.Plot, error) {
+	colors := moreland.SmoothBlueRed()
+	colors.SetMin(-1)
+	colors.SetMax(1)
+	heatmap := plotter.NewHeatMap(correlationGrid{m.Coefficients}, colors.Palette(255))
+	heatmap.Min, heatmap.Max = -1, 1
+
+	k := len(names)
+	var labels plotter.XYLabels
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			label := fmt.Sprintf("%.2f", m.Coefficients.At(i, j))
+			if i != j && m.P.At(i, j) < 0.05 {
+				label += "*"
+			}
+			labels.XYs = append(labels.XYs, plotter.XY{X: float64(j), Y: float64(k - 1 - i)})
+			labels.Labels = append(labels.Labels, label)
+		}
+	}
+	values, err := plotter.NewLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+	for i := range values.TextStyle {
+		values.TextStyle[i].Color = opts.Theme.Foreground
+		values.TextStyle[i].XAlign = text.XCenter
+		values.TextStyle[i].YAlign = text.YCenter
+	}
+
+	p := plot.New()
+	p.Title.Text = fmt.Sprintf("%s correlation matrix, * p < 0.05", correlationTitles[m.Method])
+	p.Add(heatmap, values)
+
+	reversed := make([]string, k)
+	for i, name := range names {
+		reversed[k-1-i] = name
+	}
+	p.NominalX(names...)
+	p.NominalY(reversed...)
+	return p, nil
+}
+
+// scatterMatrix draws a scatter plot of every pair of columns in a grid of
+// cells, the column of row i against the column of column j, with the
+// names of the columns on the diagonal. Each column is scaled to fill its
+// cells.
+func scatterMatrix(columns [][]float64, names []string, opts plotting.Options) (*plot.Plot, error) {
+	const margin = 0.08
+	k := len(columns)
+	scaled := make([][]float64, k)
+	for j, c := range columns {
+		lo, hi := floats.Min(c), floats.Max(c)
+		scaled[j] = make([]float64, len(c))
+		for i, v := range c {
+			scaled[j][i] = margin + (1-2*margin)*(v-lo)/(hi-lo)
+		}
+	}
+
+	var points plotter.XYs
+	var labels plotter.XYLabels
+	for i := 0; i < k; i++ {
+		top := float64(k - 1 - i)
+		for j := 0; j < k; j++ {
+			if i == j {
+				labels.XYs = append(labels.XYs, plotter.XY{X: float64(j) + 0.5, Y: top + 0.5})
+				labels.Labels = append(labels.Labels, names[j])
+				continue
+			}
+			for r := range scaled[j] {
+				points = append(points, plotter.XY{X: float64(j) + scaled[j][r], Y: top + scaled[i][r]})
+			}
+		}
+	}
+
+	p := plot.New()
+	p.Title.Text = "Scatter plot matrix"
+	p.HideAxes()
+	p.X.Min, p.X.Max = 0, float64(k)
+	p.Y.Min, p.Y.Max = 0, float64(k)
+
+	scatter, err := plotter.NewScatter(points)
+	if err != nil {
+		return nil, err
+	}
+	scatter.Color = opts.Theme.Primary
+	scatter.Radius = vg.Points(1)
+	scatter.Shape = draw.CircleGlyph{}
+	p.Add(scatter)
+
+	for b := 0; b <= k; b++ {
+		for _, edge := range []plotter.XYs{
+			{{X: float64(b), Y: 0}, {X: float64(b), Y: float64(k)}},
+			{{X: 0, Y: float64(b)}, {X: float64(k), Y: float64(b)}},
+		} {
+			line, err := plotter.NewLine(edge)
+			if err != nil {
+				return nil, err
+			}
+			line.Color = opts.Theme.Foreground
+			line.Width = vg.Points(0.5)
+			p.Add(line)
+		}
+	}
+
+	values, err := plotter.NewLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+	for i := range values.TextStyle {
+		values.TextStyle[i].Color = opts.Theme.Foreground
+		values.TextStyle[i].XAlign = text.XCenter
+		values.TextStyle[i].YAlign = text.YCenter
+	}
+	p.Add(values)
+	return p, nil
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/dataset"
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/plot"
+)
+
+// datasetQuantiles are the probabilities reported for an uploaded dataset.
+var datasetQuantiles = []float64{0.1, 0.25, 0.5, 0.75, 0.9}
+
+// readUpload reads the request body up to the upload limit, writing an
+// error response and returning false when that fails.
+func readUpload(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, app.MaxUploadBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			helpers.ErrorJSON(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("dataset is larger than %d bytes", maxErr.Limit), nil)
+			return nil, false
+		}
+		helpers.ErrorJSON(w, http.StatusBadRequest, "could not read dataset", nil)
+		return nil, false
+	}
+	return body, true
+}
+
+// readDataset reads the request body as a CSV or JSON list of numbers,
+// writing an error response and returning false when that fails.
+func readDataset(w http.ResponseWriter, r *http.Request) ([]float64, bool) {
+	body, ok := readUpload(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	values, err := dataset.ParseNumbers(body, r.Header.Get("Content-Type"))
+	if err != nil {
+		helpers.ErrorJSON(w, http.StatusBadRequest, err.Error(), nil)
+		return nil, false
+	}
+
+	return values, true
+}
+
+// datasetParams reads the histogram bin count of an uploaded dataset.
+func datasetParams(q *helpers.Query) int {
+	return q.Int("bins", 50, 1, maxBins)
+}
+
+func Dataset(w http.ResponseWriter, r *http.Request) {
+	q := helpers.NewQuery(r)
+	bins := datasetParams(q)
+	opts := chartOptions(q)
+	if !q.Valid() {
+		helpers.InvalidQuery(w, q.Errors)
+		return
+	}
+
+	values, ok := readDataset(w, r)
+	if !ok {
+		return
+	}
+
+	if len(values) < 2 {
+		helpers.ErrorJSON(w, http.StatusBadRequest, "dataset needs at least 2 numbers", nil)
+		return
+	}
+
+	// Values near the float64 limits overflow the moments, and with them
+	// the histogram bin widths
+	mean, variance := stat.MeanVariance(values, nil)
+	if math.IsInf(variance, 0) || math.IsNaN(variance) {
+		helpers.ErrorJSON(w, http.StatusBadRequest, "dataset values are too large to summarize", nil)
+		return
+	}
+
+	// Sort a copy of the data for the quantiles
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	quantiles := make([]models.Quantile, len(datasetQuantiles))
+	for i, p := range datasetQuantiles {
+		quantiles[i] = models.Quantile{P: p, Value: finite(stat.Quantile(p, stat.Empirical, sorted, nil))}
+	}
+
+	p := plot.New()
+
+	histogram, err := plotting.NewHist(values, bins)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	histogram.FillColor = opts.Theme.Primary
+
+	p.Add(histogram)
+
+	if q.Has("format") {
+		img, err := plotting.Render(p, opts)
+		if err != nil {
+			helpers.ServerError(w, err)
+			return
+		}
+
+		writeChart(w, r, q, img, opts.Format, "dataset")
+		return
+	}
+
+	svgResponse := &models.DatasetResponse{
+		Meta:      models.Meta{Schema: "dataset.v1"},
+		Count:     len(values),
+		Mean:      mean,
+		Median:    stat.Quantile(0.5, stat.Empirical, sorted, nil),
+		Variance:  variance,
+		StdDev:    math.Sqrt(variance),
+		Quantiles: quantiles,
+	}
+	// Continuous values rarely repeat, so their modes are the fullest bins
+	// of the histogram, as for the mode topic
+	if integral(values) {
+		svgResponse.Modes, svgResponse.ModeCount = modes(values)
+	} else {
+		for _, b := range modalBins(histogram.Bins) {
+			svgResponse.Modes = append(svgResponse.Modes, (b.Min+b.Max)/2)
+			svgResponse.ModeBins = append(svgResponse.ModeBins, models.Bin{Min: b.Min, Max: b.Max, Count: int(b.Weight)})
+			svgResponse.ModeCount = int(b.Weight)
+		}
+	}
+	writeJSON(w, svgResponse)
+}
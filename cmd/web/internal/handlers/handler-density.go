@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/density"
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// densityPoints is the number of values a kernel density estimate is
+// evaluated at.
+const densityPoints = 512
+
+// densityOptions control how a sample is binned and which curves are drawn
+// over its histogram.
+type densityOptions struct {
+	// binning is "fixed" for bins bins, or the name of a density.BinRule.
+	binning string
+	bins    int
+	kernel  density.Kernel
+	// rule picks the bandwidth, unless it is empty and bandwidth is set.
+	rule      density.Rule
+	bandwidth float64
+	kde       bool
+	normal    bool
+}
+
+// densityParams reads the binning and the kernel density estimate of a
+// sample histogram with bins fixed bins.
+func densityParams(q *helpers.Query, bins int) densityOptions {
+	binning := []string{"fixed"}
+	for _, r := range density.BinRules {
+		binning = append(binning, string(r))
+	}
+	kernels := make([]string, len(density.Kernels))
+	for i, k := range density.Kernels {
+		kernels[i] = k.Name
+	}
+
+	o := densityOptions{
+		binning: q.Enum("binning", "fixed", binning...),
+		bins:    bins,
+		kde:     q.Enum("kde", "true", "false", "true") == "true",
+		normal:  q.Enum("normal", "false", "false", "true") == "true",
+	}
+	o.kernel, _ = density.LookupKernel(q.Enum("kernel", density.Gaussian.Name, kernels...))
+
+	// The bandwidth is the name of a rule or a number
+	bandwidth := q.String("bandwidth", string(density.Silverman))
+	for _, r := range density.Rules {
+		if bandwidth == string(r) {
+			o.rule = r
+			return o
+		}
+	}
+	h, err := strconv.ParseFloat(bandwidth, 64)
+	q.Check(err == nil && h > 0 && h <= maxParam, "bandwidth", fmt.Sprintf("must be silverman, scott or a number in (0, %g]", maxParam))
+	o.bandwidth = h
+	return o
+}
+
+// densityPlot draws the histogram of values, as densities when the kernel
+// density estimate or the normal distribution fitted to the values is
+// drawn over it. The estimate is left out when the values are too few or
+// too alike for the bandwidth rule.
+func densityPlot(values []float64, o densityOptions, opts plotting.Options) (*plot.Plot, models.Histogram, error) {
+	info := models.Histogram{Binning: o.binning, Bins: o.bins}
+	if o.binning != "fixed" {
+		info.Bins = min(density.Bins(values, density.BinRule(o.binning)), maxBins)
+	}
+
+	p := plot.New()
+	histogram, err := plotting.NewHist(values, info.Bins)
+	if err != nil {
+		return nil, info, err
+	}
+	info.Bins, info.BinWidth = len(histogram.Bins), histogram.Width
+	histogram.FillColor = opts.Theme.Primary
+	if o.kde || o.normal {
+		histogram.Normalize(1)
+		p.Y.Label.Text = "Density"
+	}
+	p.Add(histogram)
+	if !o.kde && !o.normal {
+		return p, info, nil
+	}
+	xMin, xMax, _, _ := histogram.DataRange()
+
+	var estimate *density.Estimate
+	if o.kde {
+		h := o.bandwidth
+		if o.rule != "" {
+			h, err = density.Bandwidth(values, o.rule)
+		}
+		if err == nil {
+			estimate, _ = density.New(values, o.kernel, h)
+		}
+	}
+	if estimate != nil {
+		info.Kernel, info.Bandwidth = estimate.Kernel.Name, estimate.Bandwidth
+		lo, hi := estimate.Range()
+		xMin, xMax = min(xMin, lo), max(xMax, hi)
+
+		line, err := plotter.NewLine(densityCurve(estimate, lo, hi))
+		if err != nil {
+			return nil, info, err
+		}
+		line.Color = opts.Theme.Highlight
+		line.Width = vg.Points(2)
+		p.Add(line)
+		p.Legend.Add(fmt.Sprintf("%s KDE, h = %.4g", estimate.Kernel.Title, estimate.Bandwidth), line)
+	}
+
+	if mean, sd := stat.MeanStdDev(values, nil); o.normal && sd > 0 {
+		fitted := distuv.Normal{Mu: mean, Sigma: sd}
+		curve := make(plotter.XYs, densityPoints)
+		for i := range curve {
+			x := xMin + float64(i)*(xMax-xMin)/float64(densityPoints-1)
+			curve[i] = plotter.XY{X: x, Y: fitted.Prob(x)}
+		}
+		line, err := plotter.NewLine(curve)
+		if err != nil {
+			return nil, info, err
+		}
+		line.Color = opts.Theme.Foreground
+		line.Width = vg.Points(1.5)
+		line.Dashes = []vg.Length{vg.Points(6), vg.Points(3)}
+		p.Add(line)
+		p.Legend.Add(fmt.Sprintf("N(%.4g, %.4g²)", fitted.Mu, fitted.Sigma), line)
+	}
+	p.Legend.Top = true
+	p.X.Min, p.X.Max = xMin, xMax
+	return p, info, nil
+}
+
+// densityCurve returns the estimate at densityPoints values from lo to hi.
+func densityCurve(e *density.Estimate, lo, hi float64) plotter.XYs {
+	grid := e.Grid(lo, hi, densityPoints)
+	curve := make(plotter.XYs, len(grid))
+	for i, y := range grid {
+		curve[i] = plotter.XY{X: lo + float64(i)*(hi-lo)/float64(len(grid)-1), Y: y}
+	}
+	return curve
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// discrete is the part of a discrete distuv distribution that is charted.
+type discrete interface {
+	Prob(x float64) float64
+	CDF(x float64) float64
+}
+
+// discreteOptions are the chart settings shared by discrete distributions.
+type discreteOptions struct {
+	// overlay draws the CDF as steps over the mass function.
+	overlay bool
+	// interval is the shaded P(a <= X <= b), nil when none was asked for.
+	interval *[2]int
+}
+
+// discreteParams reads the cdf overlay switch and the a and b bounds of the
+// shaded interval. Leaving out one bound leaves that side of the plotted
+// range [lo, hi] open.
+func discreteParams(q *helpers.Query, lo, hi float64) discreteOptions {
+	o := discreteOptions{overlay: q.Enum("cdf", "false", "false", "true") == "true"}
+
+	a := q.Int("a", int(math.Ceil(lo)), -int(maxParam), int(maxParam))
+	b := q.Int("b", int(math.Floor(hi)), -int(maxParam), int(maxParam))
+	// The defaults cross when the range holds no integer, which
+	// checkSupport reports
+	if q.Has("a") || q.Has("b") || math.Ceil(lo) <= hi {
+		q.Check(a <= b, "b", "must not be less than a")
+	}
+	if q.Has("a") || q.Has("b") {
+		o.interval = &[2]int{a, b}
+	}
+	return o
+}
+
+// integers returns the whole numbers in [lo, hi], where a discrete
+// distribution has its mass.
+func integers(lo, hi float64) []float64 {
+	var x []float64
+	for k := math.Ceil(lo); k <= hi; k++ {
+		x = append(x, k)
+	}
+	return x
+}
+
+// checkSupport fails the query when [lo, hi] holds no integer or too many
+// integers to evaluate a mass function at.
+func checkSupport(q *helpers.Query, lo, hi float64) {
+	q.Check(math.Ceil(lo) <= hi, "xmax", "the range must cover at least one integer")
+	q.Check(math.Floor(hi)-math.Ceil(lo) < maxSupport, "xmax", fmt.Sprintf("the range must cover fewer than %d integers", maxSupport))
+}
+
+// intervalProbability returns P(a <= X <= b) for the interval of o, or nil
+// when there is none.
+func intervalProbability(dist discrete, o discreteOptions) *models.Interval {
+	if o.interval == nil {
+		return nil
+	}
+	a, b := o.interval[0], o.interval[1]
+	return &models.Interval{
+		A:           a,
+		B:           b,
+		Probability: dist.CDF(float64(b)) - dist.CDF(float64(a-1)),
+	}
+}
+
+// discretePlots draws the mass function of dist as stems at x and its CDF
+// as steps. The interval of o is shaded on both charts.
+func discretePlots(title string, dist discrete, x []float64, o discreteOptions, opts plotting.Options) ([]namedPlot, error) {
+	if len(x) == 0 {
+		return nil, fmt.Errorf("handlers: no integers to chart %s at", title)
+	}
+
+	pmf := plot.New()
+	pmf.Title.Text = title + ": Probability mass function"
+	pmf.X.Label.Text = "X"
+	pmf.Y.Label.Text = "P(X = x)"
+
+	inside := func(k float64) bool {
+		return o.interval != nil && k >= float64(o.interval[0]) && k <= float64(o.interval[1])
+	}
+
+	var mass, shaded plotter.XYs
+	top := 0.0
+	for _, k := range x {
+		pt := plotter.XY{X: k, Y: dist.Prob(k)}
+		top = math.Max(top, pt.Y)
+		if inside(k) {
+			shaded = append(shaded, pt)
+		} else {
+			mass = append(mass, pt)
+		}
+	}
+
+	if o.interval != nil {
+		band, err := intervalBand(o.interval, x, top, opts)
+		if err != nil {
+			return nil, err
+		}
+		pmf.Add(band)
+		pmf.Legend.Add(intervalLabel(dist, o), band)
+	}
+
+	for _, s := range []struct {
+		pts   plotter.XYs
+		color color.Color
+	}{{mass, opts.Theme.Primary}, {shaded, opts.Theme.Highlight}} {
+		if len(s.pts) == 0 {
+			continue
+		}
+		stems, err := plotting.NewStems(s.pts)
+		if err != nil {
+			return nil, err
+		}
+		stems.LineStyle.Color = s.color
+		stems.LineStyle.Width = vg.Points(1.5)
+		stems.GlyphStyle.Color = s.color
+		pmf.Add(stems)
+	}
+
+	if o.overlay {
+		steps, err := cdfSteps(dist, x, opts)
+		if err != nil {
+			return nil, err
+		}
+		steps.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+		pmf.Add(steps)
+		pmf.Legend.Add("CDF", steps)
+		pmf.Y.Label.Text = "Probability"
+	}
+	pmf.Legend.Top = true
+	pmf.Legend.Left = o.overlay
+
+	cdf := plot.New()
+	cdf.Title.Text = title + ": Cumulative distribution function"
+	cdf.X.Label.Text = "X"
+	cdf.Y.Label.Text = "P(X ≤ x)"
+	if o.interval != nil {
+		band, err := intervalBand(o.interval, x, 1, opts)
+		if err != nil {
+			return nil, err
+		}
+		cdf.Add(band)
+		cdf.Legend.Add(intervalLabel(dist, o), band)
+		cdf.Legend.Top = true
+		cdf.Legend.Left = true
+	}
+	steps, err := cdfSteps(dist, x, opts)
+	if err != nil {
+		return nil, err
+	}
+	cdf.Add(steps)
+
+	return []namedPlot{{"pmf", pmf}, {"cdf", cdf}}, nil
+}
+
+// cdfSteps draws the CDF of dist, which jumps at each integer and is flat
+// in between.
+func cdfSteps(dist discrete, x []float64, opts plotting.Options) (*plotter.Line, error) {
+	pts := make(plotter.XYs, len(x))
+	for i, k := range x {
+		pts[i] = plotter.XY{X: k, Y: dist.CDF(k)}
+	}
+	line, err := plotter.NewLine(pts)
+	if err != nil {
+		return nil, err
+	}
+	line.Color = opts.Theme.Primary
+	line.StepStyle = plotter.PostStep
+	return line, nil
+}
+
+// intervalBand shades the bars of the integers in the interval that fall
+// inside the plotted x, up to the height top.
+func intervalBand(interval *[2]int, x []float64, top float64, opts plotting.Options) (*plotter.Polygon, error) {
+	// When the interval lies outside the chart, an empty band is kept so
+	// the legend still reports its probability
+	lo, hi := float64(interval[0]), float64(interval[0])
+	if len(x) > 0 {
+		lo = math.Max(float64(interval[0]), x[0]) - 0.5
+		hi = math.Min(float64(interval[1]), x[len(x)-1]) + 0.5
+		if lo > hi {
+			lo, hi = x[0], x[0]
+		}
+	}
+	band, err := plotter.NewPolygon(plotter.XYs{{X: lo, Y: 0}, {X: hi, Y: 0}, {X: hi, Y: top}, {X: lo, Y: top}})
+	if err != nil {
+		return nil, err
+	}
+	band.Color = plotting.Translucent(opts.Theme.Highlight, 0.2)
+	band.LineStyle.Width = 0
+	return band, nil
+}
+
+func intervalLabel(dist discrete, o discreteOptions) string {
+	i := intervalProbability(dist, o)
+	return fmt.Sprintf("P(%d ≤ X ≤ %d) = %.4g", i.A, i.B, i.Probability)
+}
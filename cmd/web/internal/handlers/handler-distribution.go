@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/distribution"
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+)
+
+// maxSupport limits the integers a discrete distribution is evaluated at.
+const maxSupport = 10000
+
+// defaultQuantiles are the probabilities reported by the explorer.
+var defaultQuantiles = []float64{0.05, 0.25, 0.5, 0.75, 0.95}
+
+func init() {
+	for _, f := range distribution.Families() {
+		topics["distribution/"+f.Name] = topic{
+			distributionTopic(f),
+			"distribution.v1",
+			f.Title + " distribution",
+		}
+	}
+}
+
+func Distribution(w http.ResponseWriter, r *http.Request) {
+	name := "distribution/" + chi.URLParam(r, "name")
+	if _, ok := topics[name]; !ok {
+		helpers.ErrorJSON(w, http.StatusNotFound, fmt.Sprintf("unknown distribution %q", chi.URLParam(r, "name")), nil)
+		return
+	}
+	serveTopic(w, r, name)
+}
+
+func DistributionChart(w http.ResponseWriter, r *http.Request) {
+	serveChart(w, r, "distribution/"+chi.URLParam(r, "name"))
+}
+
+// distributionTopic returns the topic of one family: its density or mass
+// function, CDF and survival function with the summary of its moments.
+func distributionTopic(f distribution.Family) func(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	return func(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+		params := distributionParams(q, f)
+
+		// The default x range covers all but the outer 0.1% of each tail,
+		// so it can only be worked out once the parameters are known
+		dist, err := newDistribution(q, f, params, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		xMin, xMax := 0.0, 1.0
+		if dist != nil {
+			xMin, xMax = distributionRange(f, dist)
+		}
+		xMin, xMax = xRange(q, xMin, xMax)
+		var view discreteOptions
+		if f.Discrete {
+			checkSupport(q, xMin, xMax)
+			view = discreteParams(q, xMin, xMax)
+		}
+
+		probabilities := q.Floats("quantiles", defaultQuantiles, 0, 1)
+		for _, p := range probabilities {
+			q.Check(p > 0 && p < 1, "quantiles", "every probability must be strictly between 0 and 1")
+		}
+		if !q.Valid() {
+			return &models.DistributionResponse{}, nil, nil
+		}
+
+		svgResponse := &models.DistributionResponse{
+			Family:   f.Name,
+			Discrete: f.Discrete,
+			Params:   make(map[string]float64, len(params)),
+			Mean:     finite(dist.Mean()),
+			Variance: finite(dist.Variance()),
+			StdDev:   finite(math.Sqrt(dist.Variance())),
+			Skewness: finite(f.Skewness(dist, params)),
+			Entropy:  finite(f.Entropy(dist)),
+		}
+		for i, p := range f.Params {
+			svgResponse.Params[p.Name] = params[i]
+		}
+		for _, p := range probabilities {
+			svgResponse.Quantiles = append(svgResponse.Quantiles, models.Quantile{P: p, Value: finite(f.Quantile(dist, p))})
+		}
+
+		// Discrete families only have mass at the integers
+		var x []float64
+		if f.Discrete {
+			svgResponse.Interval = intervalProbability(dist, view)
+			x = integers(xMin, xMax)
+		} else {
+			x = make([]float64, curvePoints)
+			for i := range x {
+				x[i] = xMin + float64(i)*(xMax-xMin)/float64(curvePoints-1)
+			}
+		}
+
+		// Some distuv types panic outside their support, so the functions
+		// are only evaluated inside it
+		lo, hi := support(f, dist)
+		prob := func(x float64) float64 {
+			if x < lo || x > hi {
+				return 0
+			}
+			return dist.Prob(x)
+		}
+		cdf := func(x float64) float64 {
+			if x < lo {
+				return 0
+			}
+			if x > hi {
+				return 1
+			}
+			return dist.CDF(x)
+		}
+		survival := func(x float64) float64 { return 1 - cdf(x) }
+		if f.Discrete {
+			survival = dist.Survival
+		}
+
+		// Discrete families share the stem charts of the binomial and
+		// Poisson topics
+		functions := []struct {
+			name  string
+			title string
+			eval  func(float64) float64
+		}{
+			{"pdf", "Probability density function", prob},
+			{"cdf", "Cumulative distribution function", cdf},
+			{"survival", "Survival function", survival},
+		}
+		plots := []namedPlot{}
+		if f.Discrete {
+			var err error
+			plots, err = discretePlots(f.Title+" distribution", dist, x, view, opts)
+			if err != nil {
+				return nil, nil, err
+			}
+			functions = functions[2:]
+		}
+		for _, fn := range functions {
+			p, err := distributionPlot(f, x, fn.eval, opts)
+			if err != nil {
+				return nil, nil, err
+			}
+			p.Title.Text = fmt.Sprintf("%s distribution: %s", f.Title, fn.title)
+			plots = append(plots, namedPlot{fn.name, p})
+		}
+
+		return svgResponse, plots, nil
+	}
+}
+
+// distributionParams reads the parameters of family f. A parameter whose
+// name is in reserved, as it is taken by the topic itself, is read with
+// the family name as prefix, such as "binomial-n".
+func distributionParams(q *helpers.Query, f distribution.Family, reserved ...string) []float64 {
+	params := make([]float64, len(f.Params))
+	for i, p := range f.Params {
+		name := p.Name
+		for _, r := range reserved {
+			if name == r {
+				name = f.Name + "-" + name
+			}
+		}
+
+		if p.Integer {
+			params[i] = float64(q.Int(name, int(p.Default), int(p.Min), int(p.Max)))
+		} else {
+			params[i] = q.Float(name, p.Default, p.Min, p.Max)
+		}
+		if p.Positive {
+			q.Check(params[i] > 0, name, "must be greater than 0")
+		}
+	}
+	return params
+}
+
+// newDistribution builds the distribution of f with params unless an error
+// was recorded, and records inconsistent parameters as query errors. It
+// returns nil when no distribution was built. Queries from DescribeQuery
+// still get a distribution, so defaults that depend on it are documented.
+func newDistribution(q *helpers.Query, f distribution.Family, params []float64, src rand.Source) (distribution.Distribution, error) {
+	if len(q.Errors) > 0 {
+		return nil, nil
+	}
+
+	dist, err := f.New(params, src)
+	var paramErr *distribution.ParamError
+	if errors.As(err, &paramErr) {
+		q.Check(false, paramErr.Param, paramErr.Message)
+		return nil, nil
+	}
+	return dist, err
+}
+
+// distributionRange returns the central 99.8% of dist, widened by 5% on
+// each side within the support for continuous families. Heavy tails can
+// put the quantiles beyond float64, so the range is kept to the values
+// xmin and xmax accept.
+func distributionRange(f distribution.Family, dist distribution.Distribution) (float64, float64) {
+	lo, hi := f.Quantile(dist, 0.001), f.Quantile(dist, 0.999)
+	if f.Discrete {
+		hi = math.Max(hi, lo+1)
+	} else {
+		pad := (hi - lo) * 0.05
+		lower, upper := support(f, dist)
+		lo, hi = math.Max(lo-pad, lower), math.Min(hi+pad, upper)
+	}
+	return math.Max(lo, -maxParam), math.Min(hi, maxParam)
+}
+
+// support returns the interval holding all the probability of a continuous
+// distribution. Discrete distributions handle any x themselves.
+func support(f distribution.Family, dist distribution.Distribution) (float64, float64) {
+	if f.Discrete {
+		return math.Inf(-1), math.Inf(1)
+	}
+	return f.Quantile(dist, 0), f.Quantile(dist, 1)
+}
+
+// distributionPlot draws eval at x: a line for continuous families and
+// steps for the survival function of discrete ones. Points where a density
+// is infinite are left out.
+func distributionPlot(f distribution.Family, x []float64, eval func(float64) float64, opts plotting.Options) (*plot.Plot, error) {
+	p := plot.New()
+	p.X.Label.Text = "X"
+
+	pts := make(plotter.XYs, 0, len(x))
+	for _, v := range x {
+		y := eval(v)
+		if !math.IsInf(y, 0) && !math.IsNaN(y) {
+			pts = append(pts, plotter.XY{X: v, Y: y})
+		}
+	}
+
+	line, err := plotter.NewLine(pts)
+	if err != nil {
+		return nil, err
+	}
+	line.Color = opts.Theme.Primary
+	if f.Discrete {
+		// A discrete survival function drops at each integer and is flat
+		// in between
+		line.StepStyle = plotter.PostStep
+	}
+	p.Add(line)
+	return p, nil
+}
+
+// finite returns a pointer to v, or nil when v is NaN or infinite, so that
+// undefined moments are left out of the JSON.
+func finite(v float64) *float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return &v
+}
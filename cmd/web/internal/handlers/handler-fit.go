@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/distribution"
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"github.com/davidhalasz/gomath/cmd/web/internal/random"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+const (
+	// minFitSize is the smallest sample the families are fitted to.
+	minFitSize = 10
+	// maxFitSize bounds the sample, as every step of a numeric fit
+	// evaluates the likelihood of all of it.
+	maxFitSize = 10000
+	// maxFitCurves bounds the fitted densities drawn over the histogram.
+	maxFitCurves = 16
+)
+
+// fitCriteria are the measures fits can be ranked by. Lower information
+// criteria and higher log-likelihoods are better.
+var fitCriteria = []string{"aic", "bic", "log-likelihood"}
+
+func DistributionFit(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "distribution-fit")
+}
+
+// distributionFitTopic fits families of the registry to the values of x,
+// or to a sample drawn from a source family, by maximum likelihood and
+// ranks the fits by an information criterion. Unless families are named,
+// integer data is fitted by the discrete families that can be fitted and
+// other data by the continuous ones.
+func distributionFitTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	registry := distribution.Families()
+	names := make([]string, len(registry))
+	for i, f := range registry {
+		names[i] = f.Name
+	}
+
+	x := q.Floats("x", nil, -maxParam, maxParam)
+	var source distribution.Family
+	var sourceParams []float64
+	n := q.Int("n", 500, minFitSize, maxFitSize)
+	var seed uint64
+	if x == nil {
+		source, _ = distribution.Lookup(q.Enum("source", "gamma", names...))
+		sourceParams = distributionParams(q, source, "x", "n", "seed", "families", "criterion", "bins", "curves")
+		seed = q.Seed()
+	} else {
+		q.Check(len(x) >= minFitSize, "x", fmt.Sprintf("must have at least %d values", minFitSize))
+		q.Check(len(x) <= maxFitSize, "x", fmt.Sprintf("must have at most %d values", maxFitSize))
+	}
+
+	var candidates []distribution.Family
+	if raw := q.String("families", ""); raw != "" {
+		for _, name := range strings.Split(raw, ",") {
+			f, ok := distribution.Lookup(strings.TrimSpace(name))
+			q.Check(ok, "families", fmt.Sprintf("must be a comma separated list of %s", strings.Join(names, ", ")))
+			if ok {
+				candidates = append(candidates, f)
+			}
+		}
+		for _, f := range candidates {
+			// Densities and probability masses are not comparable
+			q.Check(f.Discrete == candidates[0].Discrete, "families", "must be all discrete or all continuous")
+		}
+	}
+	criterion := q.Enum("criterion", "aic", fitCriteria...)
+	bins := q.Int("bins", 40, 1, maxBins)
+	curves := q.Int("curves", 4, 1, maxFitCurves)
+
+	var dist distribution.Distribution
+	if x == nil {
+		var err error
+		if dist, err = newDistribution(q, source, sourceParams, random.New(seed)); err != nil {
+			return nil, nil, err
+		}
+	}
+	if !q.Valid() {
+		return &models.DistributionFitResponse{}, nil, nil
+	}
+
+	values := x
+	if values == nil {
+		values = make([]float64, n)
+		for i := range values {
+			values[i] = dist.Rand()
+		}
+	}
+	discrete := true
+	for _, v := range values {
+		if v != math.Trunc(v) {
+			discrete = false
+			break
+		}
+	}
+	if candidates == nil {
+		for _, f := range registry {
+			if f.Discrete == discrete && f.Fittable() {
+				candidates = append(candidates, f)
+			}
+		}
+	}
+
+	svgResponse := &models.DistributionFitResponse{
+		Seed:      seed,
+		N:         len(values),
+		Discrete:  candidates[0].Discrete,
+		Criterion: criterion,
+		Fits:      []models.DistributionFit{},
+	}
+	if x == nil {
+		svgResponse.Source = source.Name
+		svgResponse.SourceParams = familyParams(source, sourceParams)
+	}
+
+	var fits []distribution.Fit
+	for _, f := range candidates {
+		fit, err := f.Fit(values)
+		if err != nil {
+			svgResponse.Skipped = append(svgResponse.Skipped, models.SkippedFit{
+				Family: f.Name,
+				Reason: strings.TrimPrefix(err.Error(), "distribution: "),
+			})
+			continue
+		}
+		fits = append(fits, fit)
+	}
+
+	score := func(fit distribution.Fit) float64 {
+		switch criterion {
+		case "bic":
+			return fit.BIC
+		case "log-likelihood":
+			return -fit.LogLikelihood
+		}
+		return fit.AIC
+	}
+	sort.SliceStable(fits, func(i, j int) bool { return score(fits[i]) < score(fits[j]) })
+	for i, fit := range fits {
+		method := "numeric"
+		if fit.ClosedForm {
+			method = "closed-form"
+		}
+		svgResponse.Fits = append(svgResponse.Fits, models.DistributionFit{
+			Rank:          i + 1,
+			Family:        fit.Family.Name,
+			Params:        familyParams(fit.Family, fit.Params),
+			Method:        method,
+			LogLikelihood: fit.LogLikelihood,
+			AIC:           fit.AIC,
+			BIC:           fit.BIC,
+			Delta:         score(fit) - score(fits[0]),
+		})
+	}
+
+	p, err := fitPlot(values, bins, svgResponse.Discrete, fits[:min(curves, len(fits))], opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	ranking := criterion
+	if criterion != "log-likelihood" {
+		ranking = strings.ToUpper(criterion)
+	}
+	p.Title.Text = fmt.Sprintf("Maximum likelihood fits to %d values, ranked by %s", len(values), ranking)
+
+	return svgResponse, []namedPlot{{"fits", p}}, nil
+}
+
+// familyParams returns the parameters of f by name.
+func familyParams(f distribution.Family, params []float64) map[string]float64 {
+	named := make(map[string]float64, len(params))
+	for i, p := range f.Params {
+		named[p.Name] = params[i]
+	}
+	return named
+}
+
+// fitPlot draws the density histogram of values under the densities of
+// the fits, best first. Discrete fits are drawn as their probability
+// masses at the integers over one bin per integer, unless the values span
+// more than maxBins integers.
+func fitPlot(values []float64, bins int, discrete bool, fits []distribution.Fit, opts plotting.Options) (*plot.Plot, error) {
+	p := plot.New()
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "Density"
+	if discrete {
+		p.Y.Label.Text = "Probability"
+	}
+
+	var histogram *plotter.Histogram
+	var err error
+	lo, hi := floats.Min(values), floats.Max(values)
+	lattice := discrete && hi-lo < maxBins
+	if lattice {
+		histogram, err = plotting.NewLatticeHist(values, 1)
+	} else {
+		histogram, err = plotting.NewHist(values, bins)
+	}
+	if err != nil {
+		return nil, err
+	}
+	histogram.Normalize(1)
+	histogram.FillColor = plotting.Translucent(opts.Theme.Primary, 0.6)
+	p.Add(histogram)
+
+	xMin, xMax, _, yMax := histogram.DataRange()
+	var x []float64
+	if lattice {
+		x = integers(lo, hi)
+	} else {
+		x = make([]float64, curvePoints)
+		for i := range x {
+			x[i] = xMin + float64(i)*(xMax-xMin)/float64(curvePoints-1)
+			if discrete {
+				x[i] = math.Round(x[i])
+			}
+		}
+	}
+
+	for i, fit := range fits {
+		// Densities are only evaluated inside their support, and left out
+		// where they are infinite
+		lower, upper := support(fit.Family, fit.Dist)
+		pts := make(plotter.XYs, 0, len(x))
+		for _, v := range x {
+			y := 0.0
+			if v >= lower && v <= upper {
+				y = fit.Dist.Prob(v)
+			}
+			if !math.IsInf(y, 0) && !math.IsNaN(y) {
+				pts = append(pts, plotter.XY{X: v, Y: y})
+			}
+		}
+		if len(pts) == 0 {
+			continue
+		}
+
+		line, err := plotter.NewLine(pts)
+		if err != nil {
+			return nil, err
+		}
+		line.Color = opts.Theme.SeriesColor(i)
+		line.Width = vg.Points(2)
+		p.Add(line)
+		p.Legend.Add(fitLabel(fit), line)
+		if lattice {
+			marks, err := plotter.NewScatter(pts)
+			if err != nil {
+				return nil, err
+			}
+			marks.Color = line.Color
+			marks.Radius = vg.Points(2.5)
+			marks.Shape = draw.CircleGlyph{}
+			p.Add(marks)
+		}
+	}
+	p.Legend.Top = true
+
+	// A density with a pole at the edge of the support would flatten the
+	// histogram, so the axis is capped at twice its tallest bar
+	p.X.Min, p.X.Max = xMin, xMax
+	p.Y.Min = 0
+	p.Y.Max = math.Min(p.Y.Max, 2*yMax)
+	return p, nil
+}
+
+// fitLabel names a fit by its family and estimates.
+func fitLabel(fit distribution.Fit) string {
+	params := make([]string, len(fit.Params))
+	for i, p := range fit.Family.Params {
+		params[i] = fmt.Sprintf("%s = %.4g", p.Name, fit.Params[i])
+	}
+	return fmt.Sprintf("%s (%s)", fit.Family.Title, strings.Join(params, ", "))
+}
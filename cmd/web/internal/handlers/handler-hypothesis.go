@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/hypothesis"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// The example data of the tests: reaction times in seconds before and
+// after a training, die rolls, and exam grades by school type.
+var (
+	exampleBefore = []float64{5.1, 4.9, 5.6, 5.8, 6.0, 5.4, 5.3, 6.1, 5.7, 5.5, 5.9, 5.2}
+	exampleAfter  = []float64{4.8, 4.7, 5.2, 5.1, 5.9, 5.0, 5.2, 5.6, 5.3, 5.1, 5.4, 5.3}
+	exampleRolls  = []float64{8, 9, 19, 5, 8, 11}
+	exampleTable  = [][]float64{{42, 31, 17}, {28, 39, 43}}
+	exampleGroups = [][]float64{
+		{3.2, 3.8, 4.1, 3.5, 3.9, 4.4, 3.6},
+		{3.9, 4.5, 4.2, 4.8, 4.0, 4.6, 4.3},
+		{3.1, 3.4, 2.9, 3.7, 3.3, 3.0, 3.6},
+	}
+)
+
+// alternatives returns the names of the alternative hypotheses.
+func alternatives() []string {
+	names := make([]string, len(hypothesis.Alternatives))
+	for i, a := range hypothesis.Alternatives {
+		names[i] = string(a)
+	}
+	return names
+}
+
+// testError records an error caused by the data of a test against param,
+// so it is reported like any invalid parameter. Other errors are returned.
+func testError(q *helpers.Query, param string, err error) error {
+	for _, known := range []error{hypothesis.ErrTooFew, hypothesis.ErrTooMany, hypothesis.ErrLength, hypothesis.ErrNoVariance, hypothesis.ErrEmpty} {
+		if errors.Is(err, known) {
+			q.Check(false, param, strings.TrimPrefix(err.Error(), "hypothesis: "))
+			return nil
+		}
+	}
+	return err
+}
+
+// testResponse fills in the parts of a response shared by every test.
+func testResponse(name string, r hypothesis.Result, alpha float64, effect string) *models.HypothesisTestResponse {
+	lo, hi := r.Critical(alpha)
+	var critical []float64
+	for _, c := range []float64{lo, hi} {
+		if !math.IsInf(c, 0) {
+			critical = append(critical, c)
+		}
+	}
+
+	return &models.HypothesisTestResponse{
+		Test:           name,
+		Alternative:    string(r.Alternative),
+		Alpha:          alpha,
+		Statistic:      r.Statistic,
+		DF:             r.DF,
+		PValue:         r.P,
+		Reject:         r.Reject(alpha),
+		CriticalValues: critical,
+		EffectSize:     models.EffectSize{Name: effect, Value: r.EffectSize},
+	}
+}
+
+func groupSummary(name string, x []float64) models.GroupSummary {
+	mean, sd := stat.MeanStdDev(x, nil)
+	return models.GroupSummary{Name: name, N: len(x), Mean: mean, StdDev: finite(sd)}
+}
+
+func TTest(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "t-test")
+}
+
+func tTestTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	test := q.Enum("test", "one-sample", "one-sample", "welch", "paired")
+	x := q.Floats("x", exampleBefore, -maxParam, maxParam)
+	y := q.Floats("y", exampleAfter, -maxParam, maxParam)
+	mu := q.Float("mu", 5, -maxParam, maxParam)
+	alt := hypothesis.Alternative(q.Enum("alternative", string(hypothesis.TwoSided), alternatives()...))
+	alpha := q.Float("alpha", 0.05, 0.0001, 0.5)
+	q.Check(len(x) >= 2, "x", "must have at least 2 values")
+	if test != "one-sample" {
+		q.Check(len(y) >= 2, "y", "must have at least 2 values")
+	}
+	if test == "paired" {
+		q.Check(len(x) == len(y), "y", "must have as many values as x")
+	}
+	if !q.Valid() {
+		return &models.HypothesisTestResponse{}, nil, nil
+	}
+
+	var result hypothesis.Result
+	var err error
+	param := "x"
+	switch test {
+	case "one-sample":
+		result, err = hypothesis.OneSampleT(x, mu, alt)
+	case "welch":
+		result, err = hypothesis.WelchT(x, y, alt)
+		param = "y"
+	case "paired":
+		result, err = hypothesis.PairedT(x, y, alt)
+		param = "y"
+	}
+	if err != nil {
+		return &models.HypothesisTestResponse{}, nil, testError(q, param, err)
+	}
+
+	svgResponse := testResponse(test, result, alpha, "cohen-d")
+	svgResponse.Groups = []models.GroupSummary{groupSummary("x", x)}
+	if test != "one-sample" {
+		svgResponse.Groups = append(svgResponse.Groups, groupSummary("y", y))
+	}
+
+	p, err := nullPlot(result, alpha, "t", opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.Title.Text = fmt.Sprintf("Student's t distribution, df = %.4g", result.DF[0])
+
+	return svgResponse, []namedPlot{{"null", p}}, nil
+}
+
+func ChiSquareTest(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "chi-square-test")
+}
+
+func chiSquareTestTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	test := q.Enum("test", "goodness-of-fit", "goodness-of-fit", "independence")
+	observed := q.Floats("observed", exampleRolls, 0, maxParam)
+	// The expected proportions default to a uniform distribution
+	proportions := q.Floats("expected", nil, 0, maxParam)
+	table := q.FloatRows("table", exampleTable, 0, maxParam)
+	alpha := q.Float("alpha", 0.05, 0.0001, 0.5)
+	switch test {
+	case "goodness-of-fit":
+		q.Check(len(observed) >= 2, "observed", "must have at least 2 categories")
+		q.Check(proportions == nil || len(proportions) == len(observed), "expected", "must have as many values as observed")
+	case "independence":
+		q.Check(len(table) >= 2 && len(table[0]) >= 2, "table", "must have at least 2 rows and 2 columns")
+		for _, row := range table {
+			q.Check(len(row) == len(table[0]), "table", "every row must have the same length")
+		}
+	}
+	if !q.Valid() {
+		return &models.HypothesisTestResponse{}, nil, nil
+	}
+
+	var svgResponse *models.HypothesisTestResponse
+	var result hypothesis.Result
+	switch test {
+	case "goodness-of-fit":
+		if proportions == nil {
+			proportions = make([]float64, len(observed))
+			for i := range proportions {
+				proportions[i] = 1
+			}
+		}
+
+		var expected []float64
+		var err error
+		result, expected, err = hypothesis.ChiSquareGOF(observed, proportions)
+		if err != nil {
+			return &models.HypothesisTestResponse{}, nil, testError(q, "expected", err)
+		}
+		svgResponse = testResponse(test, result, alpha, "cohen-w")
+		svgResponse.Expected = [][]float64{expected}
+	case "independence":
+		var expected [][]float64
+		var err error
+		result, expected, err = hypothesis.ChiSquareIndependence(table)
+		if err != nil {
+			return &models.HypothesisTestResponse{}, nil, testError(q, "table", err)
+		}
+		svgResponse = testResponse(test, result, alpha, "cramer-v")
+		svgResponse.Expected = expected
+	}
+
+	p, err := nullPlot(result, alpha, "χ²", opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.Title.Text = fmt.Sprintf("Chi-squared distribution, df = %g", result.DF[0])
+
+	return svgResponse, []namedPlot{{"null", p}}, nil
+}
+
+func ANOVA(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "anova")
+}
+
+func anovaTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	groups := q.FloatRows("groups", exampleGroups, -maxParam, maxParam)
+	alpha := q.Float("alpha", 0.05, 0.0001, 0.5)
+	q.Check(len(groups) >= 2, "groups", "must have at least 2 groups")
+	if !q.Valid() {
+		return &models.HypothesisTestResponse{}, nil, nil
+	}
+
+	result, err := hypothesis.OneWayANOVA(groups)
+	if err != nil {
+		return &models.HypothesisTestResponse{}, nil, testError(q, "groups", err)
+	}
+
+	svgResponse := testResponse("one-way", result.Result, alpha, "eta-squared")
+	svgResponse.SSBetween = result.SSBetween
+	svgResponse.SSWithin = result.SSWithin
+	for i, g := range groups {
+		svgResponse.Groups = append(svgResponse.Groups, groupSummary(fmt.Sprintf("group %d", i+1), g))
+	}
+
+	p, err := nullPlot(result.Result, alpha, "F", opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.Title.Text = fmt.Sprintf("F distribution, df = %g and %g", result.DF[0], result.DF[1])
+
+	return svgResponse, []namedPlot{{"null", p}}, nil
+}
+
+// nullPlot draws the density of the test statistic under the null
+// hypothesis with the rejection region at level alpha shaded and the
+// observed statistic marked.
+func nullPlot(r hypothesis.Result, alpha float64, symbol string, opts plotting.Options) (*plot.Plot, error) {
+	p := plot.New()
+	p.X.Label.Text = symbol
+	p.Y.Label.Text = "Density"
+
+	// Cover the bulk of the distribution and the observed statistic;
+	// statistics that cannot be negative start at zero
+	xMin := math.Min(r.Null.Quantile(0.0005), r.Statistic)
+	xMax := math.Max(r.Null.Quantile(0.9995), r.Statistic)
+	pad := (xMax - xMin) * 0.05
+	xMin, xMax = xMin-pad, xMax+pad
+	if r.Null.CDF(0) == 0 {
+		xMin = 0
+	}
+
+	density := func(from, to float64) plotter.XYs {
+		pts := make(plotter.XYs, 0, curvePoints)
+		for i := 0; i < curvePoints; i++ {
+			x := from + float64(i)*(to-from)/float64(curvePoints-1)
+			if y := r.Null.Prob(x); !math.IsInf(y, 0) && !math.IsNaN(y) {
+				pts = append(pts, plotter.XY{X: x, Y: y})
+			}
+		}
+		return pts
+	}
+
+	// Densities that diverge at zero, such as chi-squared with one degree
+	// of freedom, are cut off above the bulk of the curve
+	curve := density(xMin, xMax)
+	top, bulk := 0.0, 0.0
+	bulkMin := r.Null.Quantile(0.1)
+	for _, pt := range curve {
+		top = math.Max(top, pt.Y)
+		if pt.X >= bulkMin {
+			bulk = math.Max(bulk, pt.Y)
+		}
+	}
+	top = math.Min(top, 1.5*bulk)
+
+	// Shade each tail of the rejection region down to the axis
+	lo, hi := r.Critical(alpha)
+	tails := [][2]float64{}
+	if !math.IsInf(lo, 0) && lo > xMin {
+		tails = append(tails, [2]float64{xMin, lo})
+	}
+	if !math.IsInf(hi, 0) && hi < xMax {
+		tails = append(tails, [2]float64{hi, xMax})
+	}
+	for i, tail := range tails {
+		pts := density(tail[0], tail[1])
+		pts = append(pts, plotter.XY{X: tail[1], Y: 0}, plotter.XY{X: tail[0], Y: 0})
+		region, err := plotter.NewPolygon(pts)
+		if err != nil {
+			return nil, err
+		}
+		region.Color = plotting.Translucent(opts.Theme.Highlight, 0.4)
+		region.LineStyle.Width = 0
+		p.Add(region)
+		if i == 0 {
+			p.Legend.Add(fmt.Sprintf("Rejection region, α = %g", alpha), region)
+		}
+	}
+
+	line, err := plotter.NewLine(curve)
+	if err != nil {
+		return nil, err
+	}
+	line.Color = opts.Theme.Primary
+	p.Add(line)
+
+	// The statistic is marked up to the curve, and stays visible far out
+	// in a tail and below a pole of the density
+	height := top / 4
+	if y := r.Null.Prob(r.Statistic); y > height {
+		height = math.Min(y, top)
+	}
+	observed, err := plotter.NewLine(plotter.XYs{{X: r.Statistic, Y: 0}, {X: r.Statistic, Y: height}})
+	if err != nil {
+		return nil, err
+	}
+	observed.Color = opts.Theme.Foreground
+	observed.Width = vg.Points(1.5)
+	observed.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+	p.Add(observed)
+	p.Legend.Add(fmt.Sprintf("%s = %.4g, p = %.4g", symbol, r.Statistic, r.P), observed)
+	p.Legend.Top = true
+
+	p.X.Min, p.X.Max = xMin, xMax
+	p.Y.Min, p.Y.Max = 0, top
+	return p, nil
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/estimation"
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"github.com/davidhalasz/gomath/cmd/web/internal/random"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+const (
+	// maxCoverageSamples limits the intervals of the coverage simulation.
+	maxCoverageSamples = 10000
+	// maxIntervalBars is the number of simulated intervals drawn.
+	maxIntervalBars = 100
+)
+
+// intervalMethods lists the methods of each parameter, the default first.
+var intervalMethods = map[string][]string{
+	"mean":       {"t", "z"},
+	"proportion": {"z", "wilson"},
+	"variance":   {"chi-square"},
+}
+
+func ConfidenceInterval(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "confidence-interval")
+}
+
+func confidenceIntervalTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	parameter := q.Enum("parameter", "mean", "mean", "proportion", "variance")
+	methods := intervalMethods[parameter]
+	method := q.Enum("method", methods[0], "t", "z", "wilson", "chi-square")
+	known := false
+	for _, m := range methods {
+		known = known || m == method
+	}
+	q.Check(known, "method", fmt.Sprintf("must be one of %s for the %s", strings.Join(methods, ", "), parameter))
+
+	// The population is normal like the incomes of the Mean topic, or
+	// Bernoulli for a proportion
+	n := q.Int("n", 30, 1, maxSampleSize)
+	mu := q.Float("mu", 27000, -maxParam, maxParam)
+	sigma := q.Float("sigma", 15000, 0, maxParam)
+	q.Check(sigma > 0, "sigma", "must be greater than 0")
+	prob := q.Float("p", 0.3, 0, 1)
+	level := q.Float("level", 0.95, 0.5, 0.9999)
+	k := q.Int("k", 100, 1, maxCoverageSamples)
+	q.Check(n*k <= maxSampleSize, "k", fmt.Sprintf("k times n must be at most %d", maxSampleSize))
+	seed := q.Seed()
+	if parameter != "proportion" && method != "z" {
+		q.Check(n >= 2, "n", "must be at least 2")
+	}
+	if !q.Valid() {
+		return &models.ConfidenceIntervalResponse{}, nil, nil
+	}
+
+	truth := map[string]float64{"mean": mu, "proportion": prob, "variance": sigma * sigma}[parameter]
+
+	// Every interval comes from a fresh sample of size n
+	localRand := random.New(seed)
+	sample := make([]float64, n)
+	estimate := func() (estimation.Interval, error) {
+		if parameter == "proportion" {
+			successes := 0
+			for i := 0; i < n; i++ {
+				if localRand.Float64() < prob {
+					successes++
+				}
+			}
+			if method == "wilson" {
+				return estimation.ProportionWilson(successes, n, level)
+			}
+			return estimation.ProportionWald(successes, n, level)
+		}
+
+		for i := range sample {
+			sample[i] = localRand.NormFloat64()*sigma + mu
+		}
+		switch {
+		case parameter == "variance":
+			return estimation.Variance(sample, level)
+		case method == "z":
+			return estimation.MeanZ(sample, sigma, level)
+		}
+		return estimation.MeanT(sample, level)
+	}
+
+	intervals := make([]estimation.Interval, k)
+	hits := 0
+	for i := range intervals {
+		interval, err := estimate()
+		if err != nil {
+			return nil, nil, err
+		}
+		intervals[i] = interval
+		if interval.Contains(truth) {
+			hits++
+		}
+	}
+
+	svgResponse := &models.ConfidenceIntervalResponse{
+		Seed:      seed,
+		Parameter: parameter,
+		Method:    method,
+		Level:     level,
+		N:         n,
+		True:      truth,
+		Estimate:  intervals[0].Estimate,
+		Lower:     intervals[0].Lower,
+		Upper:     intervals[0].Upper,
+		Coverage: models.Coverage{
+			Samples: k,
+			Hits:    hits,
+			Rate:    float64(hits) / float64(k),
+		},
+	}
+
+	p, err := coveragePlot(intervals, truth, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.Title.Text = fmt.Sprintf("%g%% confidence intervals for the %s: %d of %d cover the true value",
+		level*100, parameter, hits, k)
+
+	return svgResponse, []namedPlot{{"coverage", p}}, nil
+}
+
+// intervalBars are horizontal error bars from the lower to the upper end
+// of confidence intervals, stacked by sample.
+type intervalBars struct {
+	plotter.XYs
+	plotter.XErrors
+}
+
+// coveragePlot draws the first intervals of the simulation, one above the
+// other, with the ones that miss the true value highlighted.
+func coveragePlot(intervals []estimation.Interval, truth float64, opts plotting.Options) (*plot.Plot, error) {
+	p := plot.New()
+	p.X.Label.Text = "Interval"
+	p.Y.Label.Text = "Sample"
+
+	var hits, misses intervalBars
+	for i, interval := range intervals[:min(len(intervals), maxIntervalBars)] {
+		bars := &hits
+		if !interval.Contains(truth) {
+			bars = &misses
+		}
+		bars.XYs = append(bars.XYs, plotter.XY{X: interval.Estimate, Y: float64(i + 1)})
+		bars.XErrors = append(bars.XErrors, struct{ Low, High float64 }{
+			Low:  interval.Estimate - interval.Lower,
+			High: interval.Upper - interval.Estimate,
+		})
+	}
+
+	yMax := math.Max(float64(len(hits.XYs)+len(misses.XYs)), 1) + 1
+	for _, set := range []struct {
+		bars  intervalBars
+		label string
+		color bool
+	}{{hits, "Covers the true value", false}, {misses, "Misses the true value", true}} {
+		if len(set.bars.XYs) == 0 {
+			continue
+		}
+		bars, err := plotter.NewXErrorBars(set.bars)
+		if err != nil {
+			return nil, err
+		}
+		estimates, err := plotter.NewScatter(set.bars.XYs)
+		if err != nil {
+			return nil, err
+		}
+		bars.Color = opts.Theme.Primary
+		if set.color {
+			bars.Color = opts.Theme.Highlight
+		}
+		bars.Width = vg.Points(1.5)
+		bars.CapWidth = vg.Points(3)
+		estimates.Color = bars.Color
+		estimates.Radius = vg.Points(1.5)
+		p.Add(bars, estimates)
+		p.Legend.Add(set.label, estimates)
+	}
+
+	line, err := plotter.NewLine(plotter.XYs{{X: truth, Y: 0}, {X: truth, Y: yMax}})
+	if err != nil {
+		return nil, err
+	}
+	line.Color = opts.Theme.Foreground
+	line.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+	p.Add(line)
+	p.Legend.Add("True value", line)
+	p.Legend.Top = true
+
+	// Leave room above the bars for the legend
+	p.Y.Min, p.Y.Max = 0, yMax*1.15
+	return p, nil
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/classification"
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"github.com/davidhalasz/gomath/cmd/web/internal/random"
+	"github.com/davidhalasz/gomath/cmd/web/internal/regression"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// minLogisticSize is the smallest generated sample. Smaller samples are
+// often separated perfectly by the predictors, so the fit has no maximum.
+const minLogisticSize = 50
+
+func LogisticRegression(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "logistic-regression")
+}
+
+func logisticRegressionTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	n := q.Int("n", 500, minLogisticSize, maxSampleSize)
+	threshold := q.Float("threshold", 0.5, 0, 1)
+	seed := q.Seed()
+	if !q.Valid() {
+		return &models.LogisticRegressionResponse{}, nil, nil
+	}
+
+	// Whether a student passes an exam depends on the hours studied and
+	// the hours slept the night before
+	localRand := random.New(seed)
+	hours := make([]float64, n)
+	sleep := make([]float64, n)
+	passed := make([]float64, n)
+	actual := make([]bool, n)
+	for i := 0; i < n; i++ {
+		hours[i] = localRand.Float64() * 10
+		sleep[i] = localRand.NormFloat64()*1.5 + 7
+		logit := -7 + 0.9*hours[i] + 0.4*sleep[i]
+		if localRand.Float64() < 1/(1+math.Exp(-logit)) {
+			passed[i] = 1
+			actual[i] = true
+		}
+	}
+
+	names := []string{"intercept", "hours", "sleep"}
+	fit, err := regression.FitLogistic([][]float64{hours, sleep}, passed)
+	if errors.Is(err, regression.ErrNotConverged) {
+		// The seed drew an unlucky sample, and another seed rarely separates
+		q.Check(false, "seed", "the generated sample is perfectly separated, so the logistic fit has no maximum; try another seed")
+		return &models.LogisticRegressionResponse{}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	terms := make([]models.LogisticTerm, len(fit.Coefficients))
+	for j, c := range fit.Coefficients {
+		terms[j] = models.LogisticTerm{
+			Name:      names[j],
+			Estimate:  c.Estimate,
+			StdError:  c.StdErr,
+			ZStat:     c.T,
+			PValue:    c.P,
+			OddsRatio: math.Exp(c.Estimate),
+		}
+	}
+
+	confusion := classification.Confusion(actual, fit.Probabilities, threshold)
+	curve := classification.ROC(actual, fit.Probabilities)
+	auc := classification.AUC(curve)
+
+	// ROC curve against the diagonal of a classifier that guesses
+	roc := plot.New()
+	roc.Title.Text = fmt.Sprintf("ROC curve (AUC = %.3f)", auc)
+	roc.X.Label.Text = "False positive rate"
+	roc.Y.Label.Text = "True positive rate"
+	roc.X.Min, roc.X.Max = 0, 1
+	roc.Y.Min, roc.Y.Max = 0, 1
+
+	points := make(plotter.XYs, len(curve))
+	for i, c := range curve {
+		points[i].X = c.FalsePositiveRate
+		points[i].Y = c.TruePositiveRate
+	}
+
+	line, err := plotter.NewLine(points)
+	if err != nil {
+		return nil, nil, err
+	}
+	line.Color = opts.Theme.Primary
+	line.Width = vg.Points(2)
+
+	guess := plotter.NewFunction(func(x float64) float64 { return x })
+	guess.Color = opts.Theme.Highlight
+	guess.Dashes = []vg.Length{vg.Points(4), vg.Points(3)}
+
+	// Mark the rates at the chosen threshold
+	operating, err := plotter.NewScatter(plotter.XYs{{X: 1 - confusion.Specificity(), Y: confusion.Recall()}})
+	if err != nil {
+		return nil, nil, err
+	}
+	operating.Color = opts.Theme.Highlight
+	operating.Radius = vg.Points(4)
+
+	roc.Add(line, guess, operating)
+
+	// Fitted probabilities against hours studied, with the outcomes at 0 and 1
+	probability := plot.New()
+	probability.Title.Text = "Fitted probability of passing"
+	probability.X.Label.Text = "Hours studied"
+	probability.Y.Label.Text = "P(pass)"
+
+	outcomes := make(plotter.XYs, n)
+	fitted := make(plotter.XYs, n)
+	for i := range hours {
+		outcomes[i].X, outcomes[i].Y = hours[i], passed[i]
+		fitted[i].X, fitted[i].Y = hours[i], fit.Probabilities[i]
+	}
+
+	outcomeScatter, err := plotter.NewScatter(outcomes)
+	if err != nil {
+		return nil, nil, err
+	}
+	outcomeScatter.Color = opts.Theme.Primary
+
+	fittedScatter, err := plotter.NewScatter(fitted)
+	if err != nil {
+		return nil, nil, err
+	}
+	fittedScatter.Color = opts.Theme.Highlight
+
+	cutoff := plotter.NewFunction(func(float64) float64 { return threshold })
+	cutoff.Color = opts.Theme.Highlight
+	cutoff.Dashes = []vg.Length{vg.Points(4), vg.Points(3)}
+
+	probability.Add(outcomeScatter, fittedScatter, cutoff)
+
+	svgResponse := &models.LogisticRegressionResponse{
+		Seed:          seed,
+		Terms:         terms,
+		LogLikelihood: fit.LogLikelihood,
+		Iterations:    fit.Iterations,
+		Threshold:     threshold,
+		ConfusionMatrix: models.ConfusionMatrix{
+			TruePositives:  confusion.TruePositives,
+			FalsePositives: confusion.FalsePositives,
+			TrueNegatives:  confusion.TrueNegatives,
+			FalseNegatives: confusion.FalseNegatives,
+		},
+		Accuracy:    confusion.Accuracy(),
+		Precision:   confusion.Precision(),
+		Recall:      confusion.Recall(),
+		Specificity: confusion.Specificity(),
+		AUC:         auc,
+	}
+	return svgResponse, []namedPlot{{"roc", roc}, {"probability", probability}}, nil
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"github.com/davidhalasz/gomath/cmd/web/internal/random"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+)
+
+// modes returns every value that occurs most often in x, in increasing
+// order, and how many times each of them occurs. Strict float64 equality
+// is used, like stat.Mode, but ties are all reported instead of one.
+func modes(x []float64) ([]float64, int) {
+	counts := make(map[float64]int)
+	maxCount := 0
+	for _, v := range x {
+		counts[v]++
+		if counts[v] > maxCount {
+			maxCount = counts[v]
+		}
+	}
+
+	var result []float64
+	for v, c := range counts {
+		if c == maxCount {
+			result = append(result, v)
+		}
+	}
+	sort.Float64s(result)
+
+	return result, maxCount
+}
+
+// integral reports whether every value of x is a whole number.
+func integral(x []float64) bool {
+	for _, v := range x {
+		if v != math.Trunc(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// modalBins returns the fullest histogram bins.
+func modalBins(bins []plotter.HistogramBin) []plotter.HistogramBin {
+	maxWeight := 0.0
+	for _, b := range bins {
+		maxWeight = math.Max(maxWeight, b.Weight)
+	}
+
+	var result []plotter.HistogramBin
+	for _, b := range bins {
+		if b.Weight == maxWeight {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+func Mode(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "mode")
+}
+
+func modeTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	kind := q.Enum("kind", "discrete", "discrete", "continuous")
+	seed := q.Seed()
+
+	n, mean, stdDev, bins := sampleParams(q, 200, 27000, 15000)
+
+	// discrete data holds random ages from low to high inclusive
+	low := q.Int("low", 18, -1000000, 1000000)
+	high := q.Int("high", 90, -1000000, 1000000)
+	if kind == "discrete" {
+		q.Check(low <= high, "high", "must be at least low")
+		q.Check(high-low < maxBins, "high", fmt.Sprintf("must be at most %d above low", maxBins-1))
+	}
+	if !q.Valid() {
+		return &models.ModeResponse{}, nil, nil
+	}
+
+	localRand := random.New(seed)
+
+	// create the sample: random ages from low to high, or a normal sample
+	values := make([]float64, n)
+	for i := range values {
+		if kind == "discrete" {
+			values[i] = float64(localRand.Intn(high-low+1) + low)
+		} else {
+			values[i] = localRand.NormFloat64()*stdDev + mean
+		}
+	}
+
+	// Count the values; discrete data gets one bin per integer
+	var histBins []plotter.HistogramBin
+	svgResponse := &models.ModeResponse{Seed: seed, Kind: kind}
+	if kind == "discrete" {
+		modeValues, modeCount := modes(values)
+		svgResponse.Modes = modeValues
+		svgResponse.ModeCount = modeCount
+
+		counts := make(map[float64]int)
+		for _, v := range values {
+			counts[v]++
+		}
+		for v := low; v <= high; v++ {
+			histBins = append(histBins, plotter.HistogramBin{
+				Min:    float64(v) - 0.5,
+				Max:    float64(v) + 0.5,
+				Weight: float64(counts[float64(v)]),
+			})
+		}
+	} else {
+		histogram, err := plotting.NewHist(values, bins)
+		if err != nil {
+			return nil, nil, err
+		}
+		histBins = histogram.Bins
+
+		for _, b := range modalBins(histBins) {
+			svgResponse.Modes = append(svgResponse.Modes, (b.Min+b.Max)/2)
+			svgResponse.ModeBins = append(svgResponse.ModeBins, models.Bin{Min: b.Min, Max: b.Max, Count: int(b.Weight)})
+			svgResponse.ModeCount = int(b.Weight)
+		}
+	}
+
+	p := plot.New()
+	p.Title.Text = "Frequency"
+
+	// Draw every bin, then draw the modal bins again in the highlight color
+	frequencies := &plotter.Histogram{
+		Bins:      histBins,
+		Width:     histBins[0].Max - histBins[0].Min,
+		FillColor: opts.Theme.Primary,
+		LineStyle: plotter.DefaultLineStyle,
+	}
+	highlight := &plotter.Histogram{
+		Bins:      modalBins(histBins),
+		Width:     frequencies.Width,
+		FillColor: opts.Theme.Highlight,
+		LineStyle: plotter.DefaultLineStyle,
+	}
+
+	p.Add(frequencies, highlight)
+
+	return svgResponse, []namedPlot{{"frequency", p}}, nil
+}
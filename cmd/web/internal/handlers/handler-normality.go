@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/distribution"
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/hypothesis"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+const (
+	// minNormalitySize is the smallest sample every test accepts; the
+	// Anderson-Darling p-value needs 8 values.
+	minNormalitySize = 8
+	// maxNormalitySize is the largest sample Royston's approximation of
+	// the Shapiro-Wilk test holds for.
+	maxNormalitySize = 5000
+)
+
+func Normality(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "normality")
+}
+
+// normalityTopic tests whether the values of x, or a normal sample drawn
+// like the one of the StdVar topic, come from a normal distribution. The
+// Kolmogorov-Smirnov test and the Q-Q and P-P plots compare the sample
+// with the reference distribution: the normal fitted to the sample, or a
+// continuous family of the registry.
+func normalityTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	x := q.Floats("x", nil, -maxParam, maxParam)
+	n := q.Int("n", 200, minNormalitySize, maxNormalitySize)
+	mu := q.Float("mu", 100, -maxParam, maxParam)
+	sigma := q.Float("sigma", 100, 0, maxParam)
+	q.Check(sigma > 0, "sigma", "must be greater than 0")
+	var seed uint64
+	if x == nil {
+		seed = q.Seed()
+	} else {
+		q.Check(len(x) >= minNormalitySize, "x", fmt.Sprintf("must have at least %d values", minNormalitySize))
+		q.Check(len(x) <= maxNormalitySize, "x", fmt.Sprintf("must have at most %d values", maxNormalitySize))
+	}
+	alpha := q.Float("alpha", 0.05, 0.0001, 0.5)
+
+	references := []string{"fitted"}
+	for _, f := range distribution.Families() {
+		if !f.Discrete {
+			references = append(references, f.Name)
+		}
+	}
+	reference := q.Enum("reference", "fitted", references...)
+	var f distribution.Family
+	var params []float64
+	if reference != "fitted" {
+		f, _ = distribution.Lookup(reference)
+		params = distributionParams(q, f, "x", "n", "mu", "sigma", "seed", "alpha")
+	}
+	if !q.Valid() {
+		return &models.NormalityResponse{}, nil, nil
+	}
+
+	values := x
+	if values == nil {
+		values = normalSample(n, mu, sigma, seed)
+	}
+	mean, sd := stat.MeanStdDev(values, nil)
+
+	svgResponse := &models.NormalityResponse{
+		Seed:            seed,
+		N:               len(values),
+		Mean:            mean,
+		StdDev:          sd,
+		Skewness:        stat.Skew(values, nil),
+		ExcessKurtosis:  stat.ExKurtosis(values, nil),
+		Reference:       reference,
+		ReferenceParams: make(map[string]float64),
+		Alpha:           alpha,
+	}
+
+	// The fitted normal is tested by Lilliefors' test, as estimating its
+	// parameters from the sample makes the Kolmogorov-Smirnov p-value too
+	// large
+	var dist distribution.Distribution
+	ks := hypothesis.Lilliefors
+	ksName := "lilliefors"
+	if reference == "fitted" {
+		f, _ = distribution.Lookup("normal")
+		dist = distuv.Normal{Mu: mean, Sigma: sd}
+		svgResponse.ReferenceParams["mu"] = mean
+		svgResponse.ReferenceParams["sigma"] = sd
+	} else {
+		var err error
+		if dist, err = newDistribution(q, f, params, nil); err != nil || dist == nil {
+			return &models.NormalityResponse{}, nil, err
+		}
+		for i, p := range f.Params {
+			svgResponse.ReferenceParams[p.Name] = params[i]
+		}
+		ks = func(x []float64) (hypothesis.Fit, error) { return hypothesis.KolmogorovSmirnov(x, dist.CDF) }
+		ksName = "kolmogorov-smirnov"
+	}
+
+	for _, t := range []struct {
+		name string
+		run  func([]float64) (hypothesis.Fit, error)
+	}{
+		{"shapiro-wilk", hypothesis.ShapiroWilk},
+		{"anderson-darling", hypothesis.AndersonDarling},
+		{ksName, ks},
+		{"jarque-bera", hypothesis.JarqueBera},
+	} {
+		fit, err := t.run(values)
+		if err != nil {
+			return &models.NormalityResponse{}, nil, testError(q, "x", err)
+		}
+		svgResponse.Tests = append(svgResponse.Tests, models.GoodnessOfFit{
+			Test:      t.name,
+			Statistic: fit.Statistic,
+			PValue:    fit.P,
+			Reject:    fit.P <= alpha,
+		})
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	qq, pp := make(plotter.XYs, len(sorted)), make(plotter.XYs, len(sorted))
+	for i, v := range sorted {
+		// Plotting positions (i + 0.5)/n keep the extreme quantiles finite
+		p := (float64(i) + 0.5) / float64(len(sorted))
+		qq[i] = plotter.XY{X: f.Quantile(dist, p), Y: v}
+		pp[i] = plotter.XY{X: dist.CDF(v), Y: p}
+	}
+
+	title := f.Title
+	if reference == "fitted" {
+		title = "Fitted normal"
+	}
+	qqPlot, err := probabilityPlot(qq, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	qqPlot.Title.Text = title + " Q-Q plot"
+	qqPlot.X.Label.Text = "Theoretical quantiles"
+	qqPlot.Y.Label.Text = "Sample quantiles"
+
+	ppPlot, err := probabilityPlot(pp, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	ppPlot.Title.Text = title + " P-P plot"
+	ppPlot.X.Label.Text = "Theoretical cumulative probability"
+	ppPlot.Y.Label.Text = "Empirical cumulative probability"
+
+	return svgResponse, []namedPlot{{"qq", qqPlot}, {"pp", ppPlot}}, nil
+}
+
+// probabilityPlot draws the points over the line y = x, which they follow
+// when the sample comes from the reference distribution.
+func probabilityPlot(points plotter.XYs, opts plotting.Options) (*plot.Plot, error) {
+	p := plot.New()
+
+	lo, hi := points[0].X, points[0].X
+	for _, pt := range points {
+		lo, hi = min(lo, pt.X, pt.Y), max(hi, pt.X, pt.Y)
+	}
+	line, err := plotter.NewLine(plotter.XYs{{X: lo, Y: lo}, {X: hi, Y: hi}})
+	if err != nil {
+		return nil, err
+	}
+	line.Color = opts.Theme.Highlight
+	line.Width = vg.Points(1.5)
+	p.Add(line)
+
+	scatter, err := plotter.NewScatter(points)
+	if err != nil {
+		return nil, err
+	}
+	scatter.Color = opts.Theme.Primary
+	scatter.Radius = vg.Points(2)
+	scatter.Shape = draw.CircleGlyph{}
+	p.Add(scatter)
+	return p, nil
+}
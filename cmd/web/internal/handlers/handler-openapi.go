@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+)
+
+var (
+	openAPIOnce sync.Once
+	openAPIDoc  []byte
+	openAPIErr  error
+)
+
+// OpenAPI serves an OpenAPI 3 description of the statistics API. It is built
+// from the topic registry, the parameters each topic reads and the response
+// types, so it cannot drift from the handlers.
+func OpenAPI(w http.ResponseWriter, r *http.Request) {
+	openAPIOnce.Do(func() {
+		openAPIDoc, openAPIErr = json.Marshal(buildOpenAPI())
+	})
+	if openAPIErr != nil {
+		helpers.ServerError(w, openAPIErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIDoc)
+}
+
+type object = map[string]any
+
+func buildOpenAPI() object {
+	schemas := schemaSet{}
+	paths := object{}
+
+	errorRef := schemas.of(reflect.TypeOf(helpers.ErrorResponse{}))
+	errorResponse := func(description string) object {
+		return object{
+			"description": description,
+			"content":     object{"application/json": object{"schema": errorRef}},
+		}
+	}
+
+	// chart options are read by every route before its topic
+	chartQuery := helpers.DescribeQuery()
+	chartOptions(chartQuery)
+	chartParams := queryParams(chartQuery.Params)
+
+	names := make([]string, 0, len(topics))
+	for name := range topics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Distribution topics have their own chart route, as their names hold
+	// a slash
+	var chartTopics, families []string
+	for _, name := range names {
+		if family, ok := strings.CutPrefix(name, "distribution/"); ok {
+			families = append(families, family)
+		} else {
+			chartTopics = append(chartTopics, name)
+		}
+	}
+
+	for _, name := range names {
+		t := topics[name]
+
+		q := helpers.DescribeQuery()
+		result, _, _ := t.compute(q, plotting.DefaultOptions())
+		ref := schemas.of(reflect.TypeOf(result))
+		schemas.pin(result, t.schema)
+
+		paths["/statistics/"+name] = object{
+			"get": object{
+				"operationId": strings.ReplaceAll(name, "/", "-"),
+				"summary":     t.summary,
+				"description": "Returns the numeric result as JSON, with links to the charts. With the format parameter the chart itself is returned instead.",
+				"parameters":  append(queryParams(q.Params), chartParams...),
+				"responses": object{
+					"200": object{
+						"description": t.summary,
+						"content":     object{"application/json": object{"schema": ref}},
+					},
+					"400": errorResponse("Invalid query parameters"),
+				},
+			},
+		}
+	}
+
+	formats := make([]string, len(plotting.Formats))
+	imageContent := object{}
+	for i, f := range plotting.Formats {
+		formats[i] = string(f)
+		imageContent[f.ContentType()] = object{"schema": object{"type": "string", "format": "binary"}}
+	}
+
+	paths["/statistics/{topic}/chart.{ext}"] = object{
+		"get": object{
+			"operationId": "chart",
+			"summary":     "One chart of a topic as an image",
+			"description": "Accepts the query parameters of its topic. Responses carry an ETag; seeded and deterministic charts may be cached.",
+			"parameters": append([]object{
+				{"name": "topic", "in": "path", "required": true, "schema": object{"type": "string", "enum": chartTopics}},
+				{"name": "ext", "in": "path", "required": true, "schema": object{"type": "string", "enum": formats}},
+				{"name": "chart", "in": "query", "description": "Chart name from the charts of the JSON response", "schema": object{"type": "string"}},
+			}, chartParams...),
+			"responses": object{
+				"200": object{"description": "The chart", "content": imageContent},
+				"304": object{"description": "The chart matches If-None-Match"},
+				"400": errorResponse("Invalid query parameters"),
+				"404": errorResponse("Unknown topic or format"),
+			},
+		},
+	}
+
+	paths["/statistics/distribution/{name}/chart.{ext}"] = object{
+		"get": object{
+			"operationId": "distribution-chart",
+			"summary":     "One chart of a distribution as an image",
+			"description": "Accepts the query parameters of its distribution.",
+			"parameters": append([]object{
+				{"name": "name", "in": "path", "required": true, "schema": object{"type": "string", "enum": families}},
+				{"name": "ext", "in": "path", "required": true, "schema": object{"type": "string", "enum": formats}},
+				{"name": "chart", "in": "query", "description": "Chart name from the charts of the JSON response", "schema": object{"type": "string"}},
+			}, chartParams...),
+			"responses": object{
+				"200": object{"description": "The chart", "content": imageContent},
+				"304": object{"description": "The chart matches If-None-Match"},
+				"400": errorResponse("Invalid query parameters"),
+				"404": errorResponse("Unknown distribution or format"),
+			},
+		},
+	}
+
+	datasetQuery := helpers.DescribeQuery()
+	datasetParams(datasetQuery)
+	datasetRef := schemas.of(reflect.TypeOf(models.DatasetResponse{}))
+	schemas.pin(models.DatasetResponse{}, "dataset.v1")
+
+	paths["/statistics/dataset"] = object{
+		"post": object{
+			"operationId": "dataset",
+			"summary":     "Descriptive statistics of an uploaded dataset",
+			"parameters":  append(queryParams(datasetQuery.Params), chartParams...),
+			"requestBody": object{
+				"required": true,
+				"content": object{
+					"text/csv":         object{"schema": object{"type": "string"}},
+					"application/json": object{"schema": object{"type": "array", "items": object{"type": "number"}}},
+				},
+			},
+			"responses": object{
+				"200": object{
+					"description": "Descriptive statistics",
+					"content":     object{"application/json": object{"schema": datasetRef}},
+				},
+				"400": errorResponse("Invalid dataset or query parameters"),
+				"413": errorResponse("Dataset larger than the upload limit"),
+			},
+		},
+	}
+
+	regressionQuery := helpers.DescribeQuery()
+	multipleRegressionParams(regressionQuery)
+	regressionRef := schemas.of(reflect.TypeOf(models.MultipleRegressionResponse{}))
+	schemas.pin(models.MultipleRegressionResponse{}, "multiple-regression.v1")
+
+	paths["/statistics/multiple-regression"] = object{
+		"post": object{
+			"operationId": "multiple-regression",
+			"summary":     "Least squares regression of a response column on several predictor columns",
+			"description": "Every column other than the response is a predictor. With the format parameter the fitted or residuals chart is returned instead.",
+			"parameters": append(append(queryParams(regressionQuery.Params),
+				object{"name": "chart", "in": "query", "schema": object{"type": "string", "enum": []string{"fitted", "residuals"}}}),
+				chartParams...),
+			"requestBody": object{
+				"required": true,
+				"content": object{
+					"text/csv": object{"schema": object{"type": "string"}},
+					"application/json": object{"schema": object{
+						"type":                 "object",
+						"additionalProperties": object{"type": "array", "items": object{"type": "number"}},
+					}},
+				},
+			},
+			"responses": object{
+				"200": object{
+					"description": "Coefficients, diagnostics and the ANOVA F-test",
+					"content":     object{"application/json": object{"schema": regressionRef}},
+				},
+				"400": errorResponse("Invalid table, collinear predictors or invalid query parameters"),
+				"413": errorResponse("Dataset larger than the upload limit"),
+			},
+		},
+	}
+
+	return object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":   "gomath statistics API",
+			"version": "1",
+		},
+		"paths":      paths,
+		"components": object{"schemas": schemas},
+	}
+}
+
+func queryParams(params []helpers.Param) []object {
+	result := make([]object, len(params))
+	for i, p := range params {
+		result[i] = object{"name": p.Name, "in": "query", "schema": p}
+	}
+	return result
+}
+
+// schemaSet collects the component schemas of the Go types it has seen.
+type schemaSet map[string]object
+
+// of returns the schema of t, registering struct types as components and
+// referring to them.
+func (s schemaSet) of(t reflect.Type) object {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return s.of(t.Elem())
+	case reflect.Struct:
+		if _, ok := s[t.Name()]; !ok {
+			s[t.Name()] = object{}
+			s[t.Name()] = s.object(t)
+		}
+		return object{"$ref": "#/components/schemas/" + t.Name()}
+	case reflect.Slice:
+		return object{"type": "array", "items": s.of(t.Elem())}
+	case reflect.Map:
+		return object{"type": "object", "additionalProperties": s.of(t.Elem())}
+	case reflect.String:
+		return object{"type": "string"}
+	case reflect.Bool:
+		return object{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return object{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return object{"type": "number"}
+	}
+	return object{}
+}
+
+// pin restricts the schema property of the registered response type of v to
+// the one version it is served as.
+func (s schemaSet) pin(v any, schema string) {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	properties := s[t.Name()]["properties"].(object)
+	properties["schema"] = object{"type": "string", "enum": []string{schema}}
+}
+
+func (s schemaSet) object(t reflect.Type) object {
+	properties := object{}
+	var required []string
+
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				walk(f.Type)
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+
+			properties[name] = s.of(f.Type)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+	}
+	walk(t)
+
+	result := object{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		result["required"] = required
+	}
+	return result
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/density"
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// cumulantKinds maps the names of the quantile estimators to gonum's.
+var cumulantKinds = map[string]stat.CumulantKind{
+	"empirical":  stat.Empirical,
+	"lin-interp": stat.LinInterp,
+}
+
+func Quantiles(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "quantiles")
+}
+
+// quantilesTopic summarizes the values of x, or the incomes of the Median
+// topic when x is not given, by their quantiles and a box plot.
+func quantilesTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	x := q.Floats("x", nil, -maxParam, maxParam)
+	n := q.Int("n", 1000, 1, maxSampleSize)
+	mu := q.Float("mu", 27000, -maxParam, maxParam)
+	sigma := q.Float("sigma", 15000, 0, maxParam)
+	q.Check(sigma > 0, "sigma", "must be greater than 0")
+	var seed uint64
+	if x == nil {
+		seed = q.Seed()
+	}
+	probabilities := q.Floats("p", datasetQuantiles, 0, 1)
+	kind := q.Enum("kind", "empirical", "empirical", "lin-interp")
+	fence := q.Float("fence", 1.5, 0, 10)
+	if !q.Valid() {
+		return &models.QuantileResponse{}, nil, nil
+	}
+
+	values := x
+	if values == nil {
+		values = normalSample(n, mu, sigma, seed)
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	cumulant := cumulantKinds[kind]
+	quantile := func(p float64) float64 {
+		return stat.Quantile(p, cumulant, sorted, nil)
+	}
+
+	summary := models.FiveNumberSummary{
+		Min:    sorted[0],
+		Q1:     quantile(0.25),
+		Median: quantile(0.5),
+		Q3:     quantile(0.75),
+		Max:    sorted[len(sorted)-1],
+	}
+	iqr := summary.Q3 - summary.Q1
+
+	// Tukey's fences lie fence times the IQR beyond the quartiles
+	fences := models.Bounds{Lower: summary.Q1 - fence*iqr, Upper: summary.Q3 + fence*iqr}
+	svgResponse := &models.QuantileResponse{
+		Seed:     seed,
+		N:        len(sorted),
+		Kind:     kind,
+		Summary:  summary,
+		IQR:      iqr,
+		Fences:   fences,
+		Outliers: []float64{},
+	}
+	for _, p := range probabilities {
+		svgResponse.Quantiles = append(svgResponse.Quantiles, models.Quantile{P: p, Value: finite(quantile(p))})
+	}
+	for _, v := range sorted {
+		if v < fences.Lower || v > fences.Upper {
+			svgResponse.Outliers = append(svgResponse.Outliers, v)
+		}
+	}
+
+	box, err := boxPlot(values, summary, fences, vg.Points(80), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	boxChart := plot.New()
+	boxChart.Title.Text = "Box plot"
+	boxChart.Y.Label.Text = "Value"
+	boxChart.Add(box)
+	boxChart.NominalX("")
+
+	curve, err := violinDensity(sorted)
+	if err != nil {
+		return nil, nil, err
+	}
+	violin, err := plotting.NewViolin(vg.Points(240), 0, curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	violin.FillColor = plotting.Translucent(opts.Theme.Primary, 0.5)
+	violin.LineStyle.Color = opts.Theme.Primary
+	inner, err := boxPlot(values, summary, fences, vg.Points(16), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	violinChart := plot.New()
+	violinChart.Title.Text = "Violin plot"
+	violinChart.Y.Label.Text = "Value"
+	violinChart.Add(violin, inner)
+	violinChart.NominalX("")
+
+	return svgResponse, []namedPlot{{"box", boxChart}, {"violin", violinChart}}, nil
+}
+
+// boxPlot returns a box plot of values of width w. plotter.BoxPlot takes
+// Tukey's hinges for quartiles, so the box is redrawn from the summary
+// and fences of the chosen estimator, with the outliers highlighted.
+func boxPlot(values []float64, summary models.FiveNumberSummary, fences models.Bounds, w vg.Length, opts plotting.Options) (*plotter.BoxPlot, error) {
+	b, err := plotter.NewBoxPlot(w, 0, plotter.Values(values))
+	if err != nil {
+		return nil, err
+	}
+	b.Median, b.Quartile1, b.Quartile3 = summary.Median, summary.Q1, summary.Q3
+	b.AdjLow, b.AdjHigh = math.Inf(1), math.Inf(-1)
+	b.Outside = nil
+	for i, v := range b.Values {
+		if v < fences.Lower || v > fences.Upper {
+			b.Outside = append(b.Outside, i)
+			continue
+		}
+		b.AdjLow, b.AdjHigh = math.Min(b.AdjLow, v), math.Max(b.AdjHigh, v)
+	}
+
+	b.FillColor = plotting.Translucent(opts.Theme.Primary, 0.5)
+	b.BoxStyle.Color = opts.Theme.Foreground
+	b.MedianStyle.Color = opts.Theme.Foreground
+	b.MedianStyle.Width = vg.Points(2)
+	b.WhiskerStyle.Color = opts.Theme.Foreground
+	b.GlyphStyle.Color = opts.Theme.Highlight
+	return b, nil
+}
+
+// violinDensity returns a Gaussian kernel density estimate of the sorted
+// values from their minimum to their maximum, with Silverman's rule of
+// thumb for the bandwidth, or a bandwidth of 1 when the values are too
+// few or too alike for it.
+func violinDensity(sorted []float64) (plotter.XYs, error) {
+	h, err := density.Bandwidth(sorted, density.Silverman)
+	if err != nil {
+		h = 1
+	}
+	estimate, err := density.New(sorted, density.Gaussian, h)
+	if err != nil {
+		return nil, err
+	}
+
+	return densityCurve(estimate, sorted[0], sorted[len(sorted)-1]), nil
+}
@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/dataset"
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"github.com/davidhalasz/gomath/cmd/web/internal/random"
+	"github.com/davidhalasz/gomath/cmd/web/internal/regression"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+const (
+	// bandLevel is the coverage of the confidence and prediction bands.
+	bandLevel = 0.95
+	// bandPoints is the number of x values the bands are sampled at.
+	bandPoints = 200
+	// maxDegree keeps polynomial fits clear of severe overfitting and of an
+	// ill-conditioned design matrix.
+	maxDegree = 10
+)
+
+func LinearRegression(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "linear-regression")
+}
+
+func linearRegressionTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	n := q.Int("n", 1000, 3, maxSampleSize)
+	seed := q.Seed()
+	if !q.Valid() {
+		return &models.LinearRegressionResponse{}, nil, nil
+	}
+
+	// Generate n random points
+	localRand := random.New(seed)
+	pageSpeeds := make([]float64, n)
+	purchaseAmount := make([]float64, n)
+
+	for i := 0; i < n; i++ {
+		pageSpeeds[i] = localRand.NormFloat64()*1.0 + 3.0
+		purchaseAmount[i] = 100.0 - (pageSpeeds[i]+localRand.NormFloat64()*0.1)*3.0
+	}
+
+	fit, err := regression.FitLinear(pageSpeeds, purchaseAmount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := plot.New()
+	p.Title.Text = "Linear Regression on Random Points"
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "Y"
+
+	points := make(plotter.XYs, n)
+	xMin, xMax := math.Inf(1), math.Inf(-1)
+	for i := range pageSpeeds {
+		points[i].X = pageSpeeds[i]
+		points[i].Y = purchaseAmount[i]
+		xMin = math.Min(xMin, pageSpeeds[i])
+		xMax = math.Max(xMax, pageSpeeds[i])
+	}
+
+	scatter, err := plotter.NewScatter(points)
+	if err != nil {
+		return nil, nil, err
+	}
+	scatter.Color = opts.Theme.Primary
+
+	// Sample both bands over the observed x values; the confidence band is
+	// shaded and the wider prediction band is outlined with dashes
+	confidence := make(plotter.XYs, 0, 2*bandPoints)
+	predictionLo := make(plotter.XYs, bandPoints)
+	predictionHi := make(plotter.XYs, bandPoints)
+	for i := 0; i < bandPoints; i++ {
+		x := xMin + (xMax-xMin)*float64(i)/float64(bandPoints-1)
+		lo, _ := fit.ConfidenceBand(x, bandLevel)
+		confidence = append(confidence, plotter.XY{X: x, Y: lo})
+		predictionLo[i].X, predictionHi[i].X = x, x
+		predictionLo[i].Y, predictionHi[i].Y = fit.PredictionBand(x, bandLevel)
+	}
+	for i := bandPoints - 1; i >= 0; i-- {
+		x := confidence[i].X
+		_, hi := fit.ConfidenceBand(x, bandLevel)
+		confidence = append(confidence, plotter.XY{X: x, Y: hi})
+	}
+
+	confidenceBand, err := plotter.NewPolygon(confidence)
+	if err != nil {
+		return nil, nil, err
+	}
+	confidenceBand.Color = plotting.Translucent(opts.Theme.Highlight, 0.35)
+	confidenceBand.LineStyle.Width = 0
+
+	lower, err := plotter.NewLine(predictionLo)
+	if err != nil {
+		return nil, nil, err
+	}
+	upper, err := plotter.NewLine(predictionHi)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, l := range []*plotter.Line{lower, upper} {
+		l.Color = opts.Theme.Highlight
+		l.Dashes = []vg.Length{vg.Points(4), vg.Points(3)}
+	}
+
+	line := plotter.NewFunction(fit.Predict)
+	line.XMin = xMin
+	line.XMax = xMax
+	line.LineStyle.Width = vg.Points(3)
+	line.Color = opts.Theme.Highlight
+
+	p.Add(scatter, confidenceBand, lower, upper, line)
+	p.Legend.Add(fmt.Sprintf("%g%% confidence band", bandLevel*100), confidenceBand)
+	p.Legend.Add(fmt.Sprintf("%g%% prediction band", bandLevel*100), lower)
+	p.Legend.Top = true
+
+	fitted := make([]float64, n)
+	for i, x := range pageSpeeds {
+		fitted[i] = fit.Predict(x)
+	}
+	r, err := residualPlot(fitted, purchaseAmount, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	svgResponse := &models.LinearRegressionResponse{
+		Seed:             seed,
+		Intercept:        coefficientResponse(fit.Intercept),
+		Slope:            coefficientResponse(fit.Slope),
+		RSquared:         fit.RSquared,
+		ResidualStdError: fit.ResidualStdErr,
+		DF:               fit.DF,
+	}
+	return svgResponse, []namedPlot{{"regression", p}, {"residuals", r}}, nil
+}
+
+// residualPlot draws the residuals against the fitted values, which should
+// scatter evenly around zero when the model is adequate.
+func residualPlot(fitted, observed []float64, opts plotting.Options) (*plot.Plot, error) {
+	p := plot.New()
+	p.Title.Text = "Residuals"
+	p.X.Label.Text = "Fitted values"
+	p.Y.Label.Text = "Residuals"
+
+	residuals := make(plotter.XYs, len(fitted))
+	for i := range fitted {
+		residuals[i].X = fitted[i]
+		residuals[i].Y = observed[i] - fitted[i]
+	}
+
+	scatter, err := plotter.NewScatter(residuals)
+	if err != nil {
+		return nil, err
+	}
+	scatter.Color = opts.Theme.Primary
+
+	zero := plotter.NewFunction(func(float64) float64 { return 0 })
+	zero.Color = opts.Theme.Highlight
+
+	p.Add(scatter, zero)
+	return p, nil
+}
+
+func coefficientResponse(c regression.Coefficient) models.Coefficient {
+	return models.Coefficient{Estimate: c.Estimate, StdError: c.StdErr, TStat: c.T, PValue: c.P}
+}
+
+func PolynomialRegression(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "polynomial-regression")
+}
+
+func polynomialRegressionTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	degree := q.Int("degree", 4, 1, maxDegree)
+	n := q.Int("n", 1000, 2, maxSampleSize)
+	q.Check(n > degree+1, "n", "must be greater than degree + 1")
+	seed := q.Seed()
+	if !q.Valid() {
+		return &models.PolynomialRegressionResponse{}, nil, nil
+	}
+
+	// Purchase amounts fall off non-linearly with the page speed
+	localRand := random.New(seed)
+	pageSpeeds := make([]float64, n)
+	purchaseAmount := make([]float64, n)
+	for i := range pageSpeeds {
+		pageSpeeds[i] = localRand.NormFloat64()*1.0 + 3.0
+		purchaseAmount[i] = (localRand.NormFloat64()*10.0 + 50.0) / pageSpeeds[i]
+	}
+
+	fit, err := regression.FitPolynomial(pageSpeeds, purchaseAmount, degree)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := plot.New()
+	p.Title.Text = "Polynomial Regression"
+	p.X.Label.Text = "Page Speeds"
+	p.Y.Label.Text = "Purchase Amounts"
+
+	points := make(plotter.XYs, n)
+	xMin, xMax := math.Inf(1), math.Inf(-1)
+	for i := range pageSpeeds {
+		points[i].X = pageSpeeds[i]
+		points[i].Y = purchaseAmount[i]
+		xMin = math.Min(xMin, pageSpeeds[i])
+		xMax = math.Max(xMax, pageSpeeds[i])
+	}
+
+	scatter, err := plotter.NewScatter(points)
+	if err != nil {
+		return nil, nil, err
+	}
+	scatter.Color = opts.Theme.Primary
+
+	// Draw the fitted curve only over the observed page speeds
+	curve := plotter.NewFunction(fit.Eval)
+	curve.XMin = xMin
+	curve.XMax = xMax
+	curve.Samples = 200
+	curve.LineStyle.Width = vg.Points(3)
+	curve.Color = opts.Theme.Highlight
+
+	p.Add(scatter, curve)
+
+	svgResponse := &models.PolynomialRegressionResponse{
+		Seed:             seed,
+		Degree:           degree,
+		Coefficients:     fit.Coefficients,
+		RSquared:         fit.RSquared,
+		AdjustedRSquared: fit.AdjustedRSquared,
+	}
+	return svgResponse, []namedPlot{{"regression", p}}, nil
+}
+
+// multipleRegressionParams reads the name of the response column; an empty
+// name selects the last column.
+func multipleRegressionParams(q *helpers.Query) string {
+	return q.String("response", "")
+}
+
+func MultipleRegression(w http.ResponseWriter, r *http.Request) {
+	q := helpers.NewQuery(r)
+	response := multipleRegressionParams(q)
+	opts := chartOptions(q)
+	if !q.Valid() {
+		helpers.InvalidQuery(w, q.Errors)
+		return
+	}
+
+	body, ok := readUpload(w, r)
+	if !ok {
+		return
+	}
+
+	table, err := dataset.ParseTable(body, r.Header.Get("Content-Type"))
+	if err != nil {
+		helpers.ErrorJSON(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if len(table.Names) < 2 {
+		helpers.ErrorJSON(w, http.StatusBadRequest, "table needs a response column and at least one predictor column", nil)
+		return
+	}
+
+	if response == "" {
+		response = table.Names[len(table.Names)-1]
+	}
+	y := table.Column(response)
+	q.Check(y != nil, "response", fmt.Sprintf("the table has no column named %q", response))
+	if !q.Valid() {
+		helpers.InvalidQuery(w, q.Errors)
+		return
+	}
+
+	// Every other column is a predictor
+	var names []string
+	var predictors [][]float64
+	for i, name := range table.Names {
+		if name != response {
+			names = append(names, name)
+			predictors = append(predictors, table.Columns[i])
+		}
+	}
+
+	fit, err := regression.FitMultiple(predictors, y)
+	if err != nil {
+		helpers.ErrorJSON(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	// Observed against fitted values lie on the diagonal for a perfect fit
+	p := plot.New()
+	p.Title.Text = "Observed and fitted values"
+	p.X.Label.Text = "Fitted values"
+	p.Y.Label.Text = response
+
+	points := make(plotter.XYs, len(y))
+	for i := range y {
+		points[i].X = fit.Fitted[i]
+		points[i].Y = y[i]
+	}
+
+	scatter, err := plotter.NewScatter(points)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+	scatter.Color = opts.Theme.Primary
+
+	diagonal := plotter.NewFunction(func(x float64) float64 { return x })
+	diagonal.Color = opts.Theme.Highlight
+
+	p.Add(scatter, diagonal)
+
+	residuals, err := residualPlot(fit.Fitted, y, opts)
+	if err != nil {
+		helpers.ServerError(w, err)
+		return
+	}
+
+	if q.Has("format") {
+		renderChart(w, r, q, "multiple-regression", []namedPlot{{"fitted", p}, {"residuals", residuals}}, opts)
+		return
+	}
+
+	terms := make([]models.Term, len(fit.Coefficients))
+	terms[0] = models.Term{Name: "intercept", Coefficient: coefficientResponse(fit.Coefficients[0])}
+	for j, name := range names {
+		terms[j+1] = models.Term{Name: name, Coefficient: coefficientResponse(fit.Coefficients[j+1]), VIF: fit.VIF[j]}
+	}
+
+	svgResponse := &models.MultipleRegressionResponse{
+		Meta:             models.Meta{Schema: "multiple-regression.v1"},
+		Count:            len(y),
+		Response:         response,
+		Terms:            terms,
+		RSquared:         fit.RSquared,
+		AdjustedRSquared: fit.AdjustedRSquared,
+		ResidualStdError: fit.ResidualStdErr,
+		ANOVA: models.RegressionANOVA{
+			DFModel:    fit.ANOVA.DFModel,
+			DFResidual: fit.ANOVA.DFResidual,
+			SSModel:    fit.ANOVA.SSModel,
+			SSResidual: fit.ANOVA.SSResidual,
+			F:          fit.ANOVA.F,
+			PValue:     fit.ANOVA.P,
+		},
+	}
+	writeJSON(w, svgResponse)
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"net/http"
+	"runtime"
+	"strings"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/hypothesis"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"github.com/davidhalasz/gomath/cmd/web/internal/resampling"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+const (
+	// maxResamples limits the resamples of a bootstrap or permutation test.
+	maxResamples = 100000
+	// maxWorkers limits the goroutines that draw the resamples.
+	maxWorkers = 64
+)
+
+// resamplingParams reads the number of resamples, checked against the n
+// values of every resample, and the number of workers, where 0 stands for
+// one per CPU.
+func resamplingParams(q *helpers.Query, def, n int) resampling.Options {
+	resamples := q.Int("resamples", def, 100, maxResamples)
+	q.Check(resamples*n <= maxSampleSize, "resamples", fmt.Sprintf("resamples times the number of values must be at most %d", maxSampleSize))
+	workers := q.Int("workers", 0, 0, maxWorkers)
+	if workers == 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return resampling.Options{Resamples: resamples, Workers: workers, Seed: q.Seed()}
+}
+
+// resamplingError records an error caused by the data against param, so
+// it is reported like any invalid parameter. Other errors are returned.
+func resamplingError(q *helpers.Query, param string, err error) error {
+	for _, known := range []error{resampling.ErrTooFew, resampling.ErrLength, resampling.ErrUndefined, resampling.ErrDegenerate} {
+		if errors.Is(err, known) {
+			q.Check(false, param, strings.TrimPrefix(err.Error(), "resampling: "))
+			return nil
+		}
+	}
+	return err
+}
+
+// statisticNames returns the names of the registered statistics that take
+// one column, or every statistic when all is set.
+func statisticNames(all bool) []string {
+	var names []string
+	for _, s := range resampling.Statistics() {
+		if all || s.Columns == 1 {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+func Bootstrap(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "bootstrap")
+}
+
+// bootstrapTopic resamples the rows of x, or of the pairs of x and y for
+// statistics of two columns, and reports the percentile and BCa intervals.
+func bootstrapTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	name := q.Enum("statistic", "median", statisticNames(true)...)
+	s, _ := resampling.Lookup(name)
+	x := q.Floats("x", exampleBefore, -maxParam, maxParam)
+	y := q.Floats("y", exampleAfter, -maxParam, maxParam)
+	data := [][]float64{x}
+	if s.Columns == 2 {
+		q.Check(len(y) == len(x), "y", "must have as many values as x")
+		data = append(data, y)
+	}
+	q.Check(len(x) >= 2, "x", "must have at least 2 values")
+	o := resamplingParams(q, 2000, len(x))
+	level := q.Float("level", 0.95, 0.5, 0.9999)
+	bins := q.Int("bins", 50, 1, maxBins)
+	if !q.Valid() {
+		return &models.BootstrapResponse{}, nil, nil
+	}
+
+	b, err := resampling.NewBootstrap(s, data, o)
+	if err != nil {
+		return &models.BootstrapResponse{}, nil, resamplingError(q, "x", err)
+	}
+	bca, err := b.BCa(level)
+	if err != nil {
+		return &models.BootstrapResponse{}, nil, resamplingError(q, "x", err)
+	}
+	percentile := b.Percentile(level)
+
+	svgResponse := &models.BootstrapResponse{
+		Seed:       o.Seed,
+		Statistic:  name,
+		N:          len(x),
+		Resamples:  o.Resamples,
+		Workers:    o.Workers,
+		Level:      level,
+		Estimate:   b.Estimate,
+		StdError:   b.StdError(),
+		Bias:       b.Bias(),
+		Undefined:  b.Undefined,
+		Percentile: models.Bounds{Lower: percentile.Lower, Upper: percentile.Upper},
+		BCa:        models.Bounds{Lower: bca.Lower, Upper: bca.Upper},
+	}
+
+	p, err := replicatePlot(b.Replicates, bins, []replicateMarker{
+		{"Estimate", []float64{b.Estimate}, opts.Theme.Foreground, true},
+		{"Percentile", []float64{percentile.Lower, percentile.Upper}, opts.Theme.Foreground, false},
+		{"BCa", []float64{bca.Lower, bca.Upper}, opts.Theme.Highlight, false},
+	}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.Title.Text = fmt.Sprintf("Bootstrap distribution of the %s, %g%% intervals", strings.ToLower(s.Title), level*100)
+	p.X.Label.Text = s.Title
+
+	return svgResponse, []namedPlot{{"bootstrap", p}}, nil
+}
+
+func PermutationTest(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "permutation-test")
+}
+
+// permutationTestTopic tests whether a statistic differs between the
+// groups x and y by relabeling the pooled values at random.
+func permutationTestTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	name := q.Enum("statistic", "mean", statisticNames(false)...)
+	s, _ := resampling.Lookup(name)
+	x := q.Floats("x", exampleGroups[0], -maxParam, maxParam)
+	y := q.Floats("y", exampleGroups[1], -maxParam, maxParam)
+	q.Check(len(x) >= 2, "x", "must have at least 2 values")
+	q.Check(len(y) >= 2, "y", "must have at least 2 values")
+	alt := hypothesis.Alternative(q.Enum("alternative", string(hypothesis.TwoSided), alternatives()...))
+	alpha := q.Float("alpha", 0.05, 0.0001, 0.5)
+	o := resamplingParams(q, 10000, len(x)+len(y))
+	bins := q.Int("bins", 50, 1, maxBins)
+	if !q.Valid() {
+		return &models.PermutationTestResponse{}, nil, nil
+	}
+
+	result, err := resampling.NewPermutation(s, x, y, alt, o)
+	if err != nil {
+		return &models.PermutationTestResponse{}, nil, resamplingError(q, "x", err)
+	}
+
+	svgResponse := &models.PermutationTestResponse{
+		Seed:        o.Seed,
+		Statistic:   name,
+		Alternative: string(alt),
+		Alpha:       alpha,
+		Resamples:   o.Resamples,
+		Workers:     o.Workers,
+		Observed:    result.Observed,
+		PValue:      result.P,
+		Reject:      result.P <= alpha,
+		Groups:      []models.GroupSummary{groupSummary("x", x), groupSummary("y", y)},
+	}
+
+	// A two sided test counts differences beyond the observed one in
+	// either direction
+	observed := []float64{result.Observed}
+	if alt == hypothesis.TwoSided && result.Observed != 0 {
+		observed = append(observed, -result.Observed)
+	}
+	p, err := replicatePlot(result.Replicates, bins, []replicateMarker{
+		{"Observed difference", observed, opts.Theme.Highlight, true},
+	}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.Title.Text = fmt.Sprintf("Permutation distribution of the difference in %s, p = %.4f", strings.ToLower(s.Title), result.P)
+	p.X.Label.Text = "Difference x - y"
+
+	return svgResponse, []namedPlot{{"permutation", p}}, nil
+}
+
+// replicateMarker is a set of vertical lines drawn over the histogram of
+// the replicates, such as the bounds of an interval.
+type replicateMarker struct {
+	label  string
+	at     []float64
+	color  color.Color
+	dashed bool
+}
+
+// replicatePlot draws the density histogram of the replicates with the
+// markers reaching a little above its highest bar.
+func replicatePlot(replicates []float64, bins int, markers []replicateMarker, opts plotting.Options) (*plot.Plot, error) {
+	p := plot.New()
+	p.Y.Label.Text = "Density"
+
+	histogram, err := plotting.NewHist(replicates, bins)
+	if err != nil {
+		return nil, err
+	}
+	histogram.Normalize(1)
+	histogram.FillColor = opts.Theme.Primary
+	p.Add(histogram)
+
+	top := 0.0
+	for _, bin := range histogram.Bins {
+		top = max(top, bin.Weight)
+	}
+	for _, m := range markers {
+		var line *plotter.Line
+		for _, x := range m.at {
+			line, err = plotter.NewLine(plotter.XYs{{X: x, Y: 0}, {X: x, Y: top * 1.05}})
+			if err != nil {
+				return nil, err
+			}
+			line.Color = m.color
+			line.Width = vg.Points(2)
+			if m.dashed {
+				line.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+			}
+			p.Add(line)
+		}
+		p.Legend.Add(m.label, line)
+	}
+	p.Legend.Top = true
+
+	// Leave room above the bars for the legend
+	p.Y.Min, p.Y.Max = 0, top*1.3
+	return p, nil
+}
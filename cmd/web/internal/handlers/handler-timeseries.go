@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"github.com/davidhalasz/gomath/cmd/web/internal/random"
+	"github.com/davidhalasz/gomath/cmd/web/internal/timeseries"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+const (
+	// minTimeSeriesSize is the shortest series analysed, two periods of
+	// the shortest season.
+	minTimeSeriesSize = 4
+	maxTimeSeriesSize = 10000
+)
+
+func TimeSeries(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "time-series")
+}
+
+// timeSeriesTopic smooths the values of y, ordered by their times t when
+// given, with moving averages, draws their correlogram and decomposes them
+// into trend, seasonal and remainder components. Without values it uses n
+// months of simulated sales with a yearly season.
+func timeSeriesTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	y := q.Floats("y", nil, -maxParam, maxParam)
+	rawTimes := q.String("t", "")
+	n := q.Int("n", 120, minTimeSeriesSize, maxTimeSeriesSize)
+	var seed uint64
+	var times []time.Time
+	if y == nil {
+		seed = q.Seed()
+		q.Check(rawTimes == "", "t", "can only be given with y")
+	} else {
+		n = len(y)
+		q.Check(n >= minTimeSeriesSize, "y", fmt.Sprintf("must have at least %d values", minTimeSeriesSize))
+		q.Check(n <= maxTimeSeriesSize, "y", fmt.Sprintf("must have at most %d values", maxTimeSeriesSize))
+		if rawTimes != "" {
+			for _, field := range strings.Split(rawTimes, ",") {
+				t, err := timeseries.ParseTime(strings.TrimSpace(field))
+				if err != nil {
+					q.Check(false, "t", "must be a comma separated list of RFC 3339 times, dates or Unix seconds")
+					times = nil
+					break
+				}
+				times = append(times, t)
+			}
+			q.Check(times == nil || len(times) == n, "t", "must have one time for every value of y")
+		}
+	}
+
+	period := q.Int("period", min(12, n/2), 2, maxTimeSeriesSize/2)
+	q.Check(2*period <= n, "period", "must be at most half the number of values")
+	modelNames := make([]string, len(timeseries.Models))
+	for i, m := range timeseries.Models {
+		modelNames[i] = string(m)
+	}
+	model := timeseries.Model(q.Enum("model", string(timeseries.Additive), modelNames...))
+	window := q.Int("window", min(12, n), 1, maxTimeSeriesSize)
+	q.Check(window <= n, "window", "must be at most the number of values")
+	alpha := q.Float("alpha", 2/float64(window+1), 0, 1)
+	q.Check(alpha > 0, "alpha", "must be greater than 0")
+	// By default the correlogram covers 10·log10(n) lags, as in R, and at
+	// least two seasons
+	lags := q.Int("lags", min(max(int(10*math.Log10(float64(n))), 2*period), n-1), 1, maxTimeSeriesSize)
+	q.Check(lags < n, "lags", "must be less than the number of values")
+	level := q.Float("level", 0.95, 0.5, 0.999)
+	if !q.Valid() {
+		return &models.TimeSeriesResponse{}, nil, nil
+	}
+
+	if y == nil {
+		times, y = exampleSeries(n, seed)
+	}
+	series := timeseries.Series{Values: y}
+	if times != nil {
+		var err error
+		if series, err = timeseries.NewSeries(times, y); err != nil {
+			return &models.TimeSeriesResponse{}, nil, timeSeriesError(q, "t", err)
+		}
+	}
+	values := series.Values
+
+	sma, err := timeseries.SMA(values, window)
+	if err != nil {
+		return nil, nil, err
+	}
+	wma, err := timeseries.WMA(values, window)
+	if err != nil {
+		return nil, nil, err
+	}
+	ema, err := timeseries.EMA(values, alpha)
+	if err != nil {
+		return nil, nil, err
+	}
+	acf, err := timeseries.ACF(values, lags)
+	if err != nil {
+		return &models.TimeSeriesResponse{}, nil, timeSeriesError(q, "y", err)
+	}
+	pacf := timeseries.PACF(acf)
+	acfBands := timeseries.BartlettBands(acf, n, level)
+	pacfBand := timeseries.Band(n, level)
+	decomposition, err := timeseries.Decompose(values, period, model)
+	if err != nil {
+		return &models.TimeSeriesResponse{}, nil, timeSeriesError(q, "model", err)
+	}
+
+	mean, sd := stat.MeanStdDev(values, nil)
+	svgResponse := &models.TimeSeriesResponse{
+		Seed:   seed,
+		N:      n,
+		Values: values,
+		Mean:   mean,
+		StdDev: sd,
+		MovingAverages: models.MovingAverages{
+			Window: window,
+			Alpha:  alpha,
+			SMA:    finiteSeries(sma),
+			WMA:    finiteSeries(wma),
+			EMA:    ema,
+		},
+		Correlogram: models.Correlogram{
+			Level:    level,
+			ACF:      acf,
+			ACFBand:  acfBands,
+			PACF:     pacf[1:],
+			PACFBand: pacfBand,
+		},
+		Decomposition: models.SeasonalDecomposition{
+			Model:     string(model),
+			Period:    period,
+			Indices:   decomposition.Indices,
+			Trend:     finiteSeries(decomposition.Trend),
+			Seasonal:  decomposition.Seasonal,
+			Remainder: finiteSeries(decomposition.Remainder),
+		},
+	}
+	for _, t := range series.Times {
+		svgResponse.Times = append(svgResponse.Times, t.Format(time.RFC3339))
+	}
+
+	// Times are drawn as Unix seconds with date ticks, and values given
+	// without them by their position
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = float64(i + 1)
+		if series.Times != nil {
+			x[i] = float64(series.Times[i].Unix())
+		}
+	}
+	newPlot := func(title string) *plot.Plot {
+		p := plot.New()
+		p.Title.Text = title
+		p.X.Label.Text = "Observation"
+		if series.Times != nil {
+			p.X.Label.Text = "Time"
+			p.X.Tick.Marker = plot.TimeTicks{Format: "2006-01-02"}
+		}
+		return p
+	}
+
+	averages := newPlot(fmt.Sprintf("Moving averages over %d values", window))
+	if err := addSeries(averages, x, values, "Values", opts.Theme.Primary, 1); err != nil {
+		return nil, nil, err
+	}
+	for i, s := range []struct {
+		label  string
+		values []float64
+	}{
+		{fmt.Sprintf("SMA(%d)", window), sma},
+		{fmt.Sprintf("WMA(%d)", window), wma},
+		{fmt.Sprintf("EMA, α = %.4g", alpha), ema},
+	} {
+		if err := addSeries(averages, x, s.values, s.label, opts.Theme.SeriesColor(i), 2); err != nil {
+			return nil, nil, err
+		}
+	}
+	averages.Legend.Top = true
+
+	acfPlot, err := correlogramPlot(acf, acfBands, 0, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	acfPlot.Title.Text = fmt.Sprintf("Autocorrelation with %.4g%% Bartlett bands", level*100)
+	acfPlot.Y.Label.Text = "ACF"
+
+	bands := make([]float64, len(pacf))
+	for i := range bands {
+		bands[i] = pacfBand
+	}
+	pacfPlot, err := correlogramPlot(pacf, bands, 1, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	pacfPlot.Title.Text = fmt.Sprintf("Partial autocorrelation with %.4g%% bands", level*100)
+	pacfPlot.Y.Label.Text = "PACF"
+
+	// The fitted values combine the trend and the seasonal effects like
+	// the model does
+	fitted := make([]float64, n)
+	for i := range fitted {
+		if model == timeseries.Multiplicative {
+			fitted[i] = decomposition.Trend[i] * decomposition.Seasonal[i]
+		} else {
+			fitted[i] = decomposition.Trend[i] + decomposition.Seasonal[i]
+		}
+	}
+	title := fmt.Sprintf("%s%s decomposition, period %d", strings.ToUpper(string(model[:1])), model[1:], period)
+	trendPlot := newPlot(title + ": trend")
+	if err := addSeries(trendPlot, x, values, "Values", opts.Theme.Primary, 1); err != nil {
+		return nil, nil, err
+	}
+	if err := addSeries(trendPlot, x, decomposition.Trend, "Trend", opts.Theme.Highlight, 2); err != nil {
+		return nil, nil, err
+	}
+	if err := addSeries(trendPlot, x, fitted, "Trend and season", opts.Theme.SeriesColor(1), 1.5); err != nil {
+		return nil, nil, err
+	}
+	trendPlot.Legend.Top = true
+
+	seasonalPlot := newPlot(title + ": seasonal component")
+	if err := addSeries(seasonalPlot, x, decomposition.Seasonal, "", opts.Theme.Primary, 1.5); err != nil {
+		return nil, nil, err
+	}
+
+	// Remainders scatter about 0 in the additive and about 1 in the
+	// multiplicative model
+	remainderPlot := newPlot(title + ": remainder")
+	base := 0.0
+	if model == timeseries.Multiplicative {
+		base = 1
+	}
+	reference, err := plotter.NewLine(plotter.XYs{{X: x[0], Y: base}, {X: x[n-1], Y: base}})
+	if err != nil {
+		return nil, nil, err
+	}
+	reference.Color = opts.Theme.Highlight
+	remainderPlot.Add(reference)
+	remainders := make(plotter.XYs, 0, n)
+	for i, r := range decomposition.Remainder {
+		if !math.IsNaN(r) {
+			remainders = append(remainders, plotter.XY{X: x[i], Y: r})
+		}
+	}
+	scatter, err := plotter.NewScatter(remainders)
+	if err != nil {
+		return nil, nil, err
+	}
+	scatter.Color = opts.Theme.Primary
+	scatter.Radius = vg.Points(2)
+	scatter.Shape = draw.CircleGlyph{}
+	remainderPlot.Add(scatter)
+
+	return svgResponse, []namedPlot{
+		{"moving-averages", averages},
+		{"acf", acfPlot},
+		{"pacf", pacfPlot},
+		{"trend", trendPlot},
+		{"seasonal", seasonalPlot},
+		{"remainder", remainderPlot},
+	}, nil
+}
+
+// exampleSeries returns n months of sales from January 2015, growing
+// linearly with a yearly season and normal noise.
+func exampleSeries(n int, seed uint64) ([]time.Time, []float64) {
+	localRand := random.New(seed)
+	times := make([]time.Time, n)
+	values := make([]float64, n)
+	start := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := range values {
+		times[i] = start.AddDate(0, i, 0)
+		season := 25 * math.Sin(2*math.Pi*float64(i)/12)
+		values[i] = 200 + 1.5*float64(i) + season + localRand.NormFloat64()*8
+	}
+	return times, values
+}
+
+// timeSeriesError records an error caused by the series, so it is
+// reported like any invalid parameter. Other errors are returned.
+func timeSeriesError(q *helpers.Query, param string, err error) error {
+	for _, known := range []error{timeseries.ErrDuplicate, timeseries.ErrConstant, timeseries.ErrNonPositive} {
+		if errors.Is(err, known) {
+			q.Check(false, param, strings.TrimPrefix(err.Error(), "timeseries: "))
+			return nil
+		}
+	}
+	return err
+}
+
+// finiteSeries returns the values of a series with NaN replaced by nil,
+// so points without a value are null in the JSON.
+func finiteSeries(values []float64) []*float64 {
+	result := make([]*float64, len(values))
+	for i, v := range values {
+		result[i] = finite(v)
+	}
+	return result
+}
+
+// addSeries draws y against x as a line, leaving out the points where y is
+// NaN, and adds it to the legend unless label is empty.
+func addSeries(p *plot.Plot, x, y []float64, label string, c color.Color, width float64) error {
+	pts := make(plotter.XYs, 0, len(y))
+	for i, v := range y {
+		if !math.IsNaN(v) {
+			pts = append(pts, plotter.XY{X: x[i], Y: v})
+		}
+	}
+	line, err := plotter.NewLine(pts)
+	if err != nil {
+		return err
+	}
+	line.Color = c
+	line.Width = vg.Points(width)
+	p.Add(line)
+	if label != "" {
+		p.Legend.Add(label, line)
+	}
+	return nil
+}
+
+// correlogramPlot draws the correlations from lag first on as stems inside
+// the bands ±bands, which are dashed.
+func correlogramPlot(correlations, bands []float64, first int, opts plotting.Options) (*plot.Plot, error) {
+	p := plot.New()
+	p.X.Label.Text = "Lag"
+
+	pts := make(plotter.XYs, 0, len(correlations))
+	for k := first; k < len(correlations); k++ {
+		pts = append(pts, plotter.XY{X: float64(k), Y: correlations[k]})
+	}
+	stems, err := plotting.NewStems(pts)
+	if err != nil {
+		return nil, err
+	}
+	stems.LineStyle.Color = opts.Theme.Primary
+	stems.LineStyle.Width = vg.Points(1.5)
+	stems.GlyphStyle.Color = opts.Theme.Primary
+	p.Add(stems)
+
+	// The band at lag 0 is 0, so the bands start at lag 1
+	for _, sign := range []float64{1, -1} {
+		band := make(plotter.XYs, 0, len(bands))
+		for k := max(first, 1); k < len(bands); k++ {
+			band = append(band, plotter.XY{X: float64(k), Y: sign * bands[k]})
+		}
+		if len(band) == 0 {
+			continue
+		}
+		line, err := plotter.NewLine(band)
+		if err != nil {
+			return nil, err
+		}
+		line.Color = opts.Theme.Highlight
+		line.Dashes = []vg.Length{vg.Points(4), vg.Points(3)}
+		p.Add(line)
+	}
+	// Both correlograms share the axis from lag 0, which also keeps the
+	// ticks on whole lags
+	p.X.Min = 0
+	return p, nil
+}
@@ -1,27 +1,19 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"image/color"
-	"image/png"
-	"log"
-	"math/rand"
 	"net/http"
 	"sort"
-	"time"
 
 	"github.com/davidhalasz/gomath/cmd/web/internal/config"
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+	"github.com/davidhalasz/gomath/cmd/web/internal/plotting"
+	"github.com/davidhalasz/gomath/cmd/web/internal/random"
 	"github.com/davidhalasz/gomath/cmd/web/internal/render"
 	"gonum.org/v1/gonum/stat"
 	"gonum.org/v1/gonum/stat/distuv"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
-	"gonum.org/v1/plot/plotutil"
-	"gonum.org/v1/plot/vg"
-	"gonum.org/v1/plot/vg/draw"
-	"gonum.org/v1/plot/vg/vgimg"
 )
 
 var app *config.AppConfig
@@ -30,31 +22,43 @@ func NewHandlers(a *config.AppConfig) {
 	app = a
 }
 
-type GnumPlotResponse struct {
-	MeanPNG             []byte  `json:"mean_png"`
-	Mean                float64 `json:"mean"`
-	MedianPNG           []byte  `json:"median_png"`
-	Median              float64 `json:"median"`
-	StdDev              float64 `json:"std_dev"`
-	Variance            float64 `json:"variance"`
-	StdDevVarPNG        []byte  `json:"std_dev_var_png"`
-	PDFPNG              []byte  `json:"pdf_png"`
-	PMFPNG              []byte  `json:"pmf_png"`
-	PoissonPNG          []byte  `json:"poisson_png"`
-	Covariance1PNG      []byte  `json:"covariance1_png"`
-	Covariance1         float64 `json:"covariance1"`
-	Covariance2PNG      []byte  `json:"covariance2_png"`
-	Covariance2         float64 `json:"covariance2"`
-	Correlation         float64 `json:"correlation"`
-	LinearRegressionR   float64 `json:"linearRegressionR"`
-	LinearRegression    []byte  `json:"linearRegression"`
-	PolynomalRegression []byte  `json:"polynomalRegression"`
+// Limits for the query parameters accepted by the statistics handlers.
+const (
+	maxSampleSize = 1000000
+	maxBins       = 1000
+	maxParam      = 1e9
+	minScale      = 1e-9
+	curvePoints   = 6001
+)
+
+// sampleParams reads the size, mean, standard deviation and histogram bin
+// count of a normal sample, falling back to the given defaults.
+func sampleParams(q *helpers.Query, n int, mu, sigma float64) (int, float64, float64, int) {
+	n = q.Int("n", n, 1, maxSampleSize)
+	mu = q.Float("mu", mu, -maxParam, maxParam)
+	sigma = q.Float("sigma", sigma, 0, maxParam)
+	q.Check(sigma > 0, "sigma", "must be greater than 0")
+	bins := q.Int("bins", 50, 1, maxBins)
+	return n, mu, sigma, bins
+}
+
+// normalSample draws n values from the normal distribution with mean mu
+// and standard deviation sigma, with the generator seeded by seed.
+func normalSample(n int, mu, sigma float64, seed uint64) []float64 {
+	localRand := random.New(seed)
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = localRand.NormFloat64()*sigma + mu
+	}
+	return values
 }
 
-var plotColor = map[string]uint8{
-	"r": uint8(71),
-	"g": uint8(85),
-	"b": uint8(105),
+// xRange reads the xmin and xmax parameters of a plotted curve.
+func xRange(q *helpers.Query, xMin, xMax float64) (float64, float64) {
+	xMin = q.Float("xmin", xMin, -maxParam, maxParam)
+	xMax = q.Float("xmax", xMax, -maxParam, maxParam)
+	q.Check(xMin < xMax, "xmax", "must be greater than xmin")
+	return xMin, xMax
 }
 
 func HomePage(w http.ResponseWriter, r *http.Request) {
@@ -70,37 +74,28 @@ func StatisticsPage(w http.ResponseWriter, r *http.Request) {
 }
 
 func Mean(w http.ResponseWriter, r *http.Request) {
-	// Use current time as a seed for random number generation
-	seed := time.Now().UnixNano()
-	localRand := rand.New(rand.NewSource(seed))
-
-	// sample number
-	n := 10000
+	serveTopic(w, r, "mean")
+}
 
-	// mean and standard deviation
-	mean := 27000.0
-	stdDev := 15000.0
+func meanTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	n, mean, stdDev, bins := sampleParams(q, 10000, 27000, 15000)
+	o := densityParams(q, bins)
+	seed := q.Seed()
+	if !q.Valid() {
+		return &models.MeanResponse{}, nil, nil
+	}
 
 	// create normalized sample
-	incomes := make([]float64, n)
-	for i := range incomes {
-		incomes[i] = localRand.NormFloat64()*stdDev + mean
-	}
+	incomes := normalSample(n, mean, stdDev, seed)
 
 	// get average
 	meanValue := stat.Mean(incomes, nil)
 
-	// New Histogram
-	p := plot.New()
-
-	// add incomes to histogram
-	values := make(plotter.Values, len(incomes))
-	copy(values, incomes)
-	histogram, _ := plotter.NewHist(values, 50)
-
-	histogram.FillColor = color.NRGBA{plotColor["r"], plotColor["g"], plotColor["b"], 255}
-
-	p.Add(histogram)
+	// Histogram with the density estimate drawn over it
+	p, histogram, err := densityPlot(incomes, o, opts)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// if zou would like to create png file
 	//
@@ -124,52 +119,23 @@ func Mean(w http.ResponseWriter, r *http.Request) {
 	// 	log.Fatalf("could not close out.png: %v", err)
 	// }
 
-	// Create an Image Canvas to draw the plot
-	imgCanvas := vgimg.New(vg.Points(800), vg.Points(400))
-	dc := draw.New(imgCanvas)
-
-	// Set up the plot and draw it onto the canvas
-	p.Draw(dc)
-
-	// Create a buffer to store PNG data
-	var pngBuffer bytes.Buffer
-
-	// Encode the image to PNG and write to the buffer
-	err := png.Encode(&pngBuffer, imgCanvas.Image())
-	if err != nil {
-		log.Printf("error encoding PNG: %v\n", err)
-		return
-	}
-
-	// send JSON response
-	svgResponse := GnumPlotResponse{MeanPNG: pngBuffer.Bytes(), Mean: meanValue}
-	jsonResponse, err := json.Marshal(svgResponse)
-	if err != nil {
-		fmt.Fprintln(w, "Error sending json:", err)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(jsonResponse)
+	return &models.MeanResponse{Seed: seed, Mean: meanValue, Histogram: histogram}, []namedPlot{{"histogram", p}}, nil
 }
 
 func Median(w http.ResponseWriter, r *http.Request) {
-	// Use current time as a seed for random number generation
-	seed := time.Now().UnixNano()
-	localRand := rand.New(rand.NewSource(seed))
-
-	// sample number
-	n := 10000
+	serveTopic(w, r, "median")
+}
 
-	// mean and standard deviation
-	mean := 27000.0
-	stdDev := 15000.0
+func medianTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	n, mean, stdDev, bins := sampleParams(q, 10000, 27000, 15000)
+	o := densityParams(q, bins)
+	seed := q.Seed()
+	if !q.Valid() {
+		return &models.MedianResponse{}, nil, nil
+	}
 
 	// create normalized sample
-	incomes := make([]float64, n)
-	for i := range incomes {
-		incomes[i] = localRand.NormFloat64()*stdDev + mean
-	}
+	incomes := normalSample(n, mean, stdDev, seed)
 
 	// Sort the incomes slice
 	sort.Float64s(incomes)
@@ -177,109 +143,67 @@ func Median(w http.ResponseWriter, r *http.Request) {
 	// Calculate the median
 	medianValue := stat.Quantile(0.5, stat.Empirical, incomes, nil)
 
-	// New Histogram
-	p := plot.New()
-
-	// add incomes to histogram
-	values := make(plotter.Values, len(incomes))
-	copy(values, incomes)
-	histogram, _ := plotter.NewHist(values, 50)
-
-	histogram.FillColor = color.NRGBA{plotColor["r"], plotColor["g"], plotColor["b"], 255}
-
-	p.Add(histogram)
-
-	// Create an Image Canvas to draw the plot
-	imgCanvas := vgimg.New(vg.Points(800), vg.Points(400))
-	dc := draw.New(imgCanvas)
-
-	// Set up the plot and draw it onto the canvas
-	p.Draw(dc)
-
-	// Create a buffer to store PNG data
-	var pngBuffer bytes.Buffer
-
-	// Encode the image to PNG and write to the buffer
-	err := png.Encode(&pngBuffer, imgCanvas.Image())
-	if err != nil {
-		log.Printf("error encoding PNG: %v\n", err)
-		return
-	}
-
-	// send JSON response
-	svgResponse := GnumPlotResponse{MedianPNG: pngBuffer.Bytes(), Median: medianValue}
-	jsonResponse, err := json.Marshal(svgResponse)
+	// Histogram with the density estimate drawn over it
+	p, histogram, err := densityPlot(incomes, o, opts)
 	if err != nil {
-		fmt.Fprintln(w, "Error sending json:", err)
-		return
+		return nil, nil, err
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(jsonResponse)
+	return &models.MedianResponse{Seed: seed, Median: medianValue, Histogram: histogram}, []namedPlot{{"histogram", p}}, nil
 }
 
 func StdVar(w http.ResponseWriter, r *http.Request) {
-	seed := time.Now().UnixNano()
-	localRand := rand.New(rand.NewSource(seed))
-
-	n := 10000
-	mean := 100.0
-	stdDev := 100.0
+	serveTopic(w, r, "std-deviation-variance")
+}
 
-	incomes := make([]float64, n)
-	for i := range incomes {
-		incomes[i] = localRand.NormFloat64()*stdDev + mean
+func stdVarTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	n, mean, stdDev, bins := sampleParams(q, 10000, 100, 100)
+	// The sample standard deviation divides by n-1
+	q.Check(n >= 2, "n", "must be at least 2")
+	o := densityParams(q, bins)
+	seed := q.Seed()
+	if !q.Valid() {
+		return &models.StdVarResponse{}, nil, nil
 	}
 
+	incomes := normalSample(n, mean, stdDev, seed)
+
 	stdDeviation := stat.StdDev(incomes, nil)
 
 	variance := stat.Variance(incomes, nil)
 
-	p := plot.New()
-
-	values := make(plotter.Values, len(incomes))
-	copy(values, incomes)
-	histogram, _ := plotter.NewHist(values, 50)
-
-	histogram.FillColor = color.NRGBA{plotColor["r"], plotColor["g"], plotColor["b"], 255}
-
-	p.Add(histogram)
-
-	imgCanvas := vgimg.New(vg.Points(800), vg.Points(400))
-	dc := draw.New(imgCanvas)
-
-	p.Draw(dc)
-
-	var pngBuffer bytes.Buffer
-
-	err := png.Encode(&pngBuffer, imgCanvas.Image())
+	p, histogram, err := densityPlot(incomes, o, opts)
 	if err != nil {
-		log.Printf("error encoding PNG: %v\n", err)
-		return
+		return nil, nil, err
 	}
 
-	svgResponse := GnumPlotResponse{StdDevVarPNG: pngBuffer.Bytes(), StdDev: stdDeviation, Variance: variance}
-	jsonResponse, err := json.Marshal(svgResponse)
-	if err != nil {
-		fmt.Fprintln(w, "Error sending json:", err)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(jsonResponse)
+	return &models.StdVarResponse{Seed: seed, StdDev: stdDeviation, Variance: variance, Histogram: histogram}, []namedPlot{{"histogram", p}}, nil
 }
 
 func PDF(w http.ResponseWriter, r *http.Request) {
-	// Create a normal distribution with mean 0 and standard deviation 1
+	serveTopic(w, r, "pdf")
+}
+
+func pdfTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	mu := q.Float("mu", 0, -maxParam, maxParam)
+	// The density peaks at 1/(sigma*sqrt(2*pi)), which a tiny sigma takes
+	// past what the chart axes can span
+	sigma := q.Float("sigma", 1, minScale, maxParam)
+	xMin, xMax := xRange(q, -3, 3)
+	if !q.Valid() {
+		return &models.NormalPDFResponse{}, nil, nil
+	}
+
+	// Create a normal distribution with the given mean and standard deviation
 	dist := distuv.Normal{
-		Mu:    0,
-		Sigma: 1,
+		Mu:    mu,
+		Sigma: sigma,
 	}
 
 	// Create a range of x values
-	x := make([]float64, 6001)
+	x := make([]float64, curvePoints)
 	for i := range x {
-		x[i] = -3 + float64(i)*0.001
+		x[i] = xMin + float64(i)*(xMax-xMin)/float64(curvePoints-1)
 	}
 
 	// Create a plotter.XYs to hold the x, y values
@@ -296,39 +220,30 @@ func PDF(w http.ResponseWriter, r *http.Request) {
 	// Create a line plot
 	line, err := plotter.NewLine(pts)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
-	line.Color = color.NRGBA{plotColor["r"], plotColor["g"], plotColor["b"], 255}
+	line.Color = opts.Theme.Primary
 
 	p.Add(line)
 
-	imgCanvas := vgimg.New(vg.Points(800), vg.Points(400))
-	dc := draw.New(imgCanvas)
-
-	p.Draw(dc)
-
-	var pngBuffer bytes.Buffer
-
-	err = png.Encode(&pngBuffer, imgCanvas.Image())
-	if err != nil {
-		log.Printf("error encoding PNG: %v\n", err)
-		return
-	}
-
-	svgResponse := GnumPlotResponse{PDFPNG: pngBuffer.Bytes()}
-	jsonResponse, err := json.Marshal(svgResponse)
-	if err != nil {
-		fmt.Fprintln(w, "Error sending json:", err)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(jsonResponse)
+	return &models.NormalPDFResponse{Mu: mu, Sigma: sigma}, []namedPlot{{"pdf", p}}, nil
 }
 
 func Binomial(w http.ResponseWriter, r *http.Request) {
-	n := 10.0
-	p := 0.5
+	serveTopic(w, r, "binomial")
+}
+
+func binomialTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	n := float64(q.Int("n", 10, 1, 10000))
+	p := q.Float("p", 0.5, 0, 1)
+	// The masses of gonum's binomial are NaN at p = 0 and p = 1
+	q.Check(p > 0 && p < 1, "p", "must be strictly between 0 and 1")
+	xMin, xMax := xRange(q, 0, n)
+	checkSupport(q, xMin, xMax)
+	view := discreteParams(q, xMin, xMax)
+	if !q.Valid() {
+		return &models.BinomialResponse{}, nil, nil
+	}
 
 	// Define the binomial distribution
 	dist := distuv.Binomial{
@@ -336,156 +251,75 @@ func Binomial(w http.ResponseWriter, r *http.Request) {
 		P: p,
 	}
 
-	// Create a range of x values
-	x := make([]float64, 10000)
-	for i := range x {
-		x[i] = float64(i) * 0.001
-	}
-
-	// Create a plotter.XYs to hold the x, y values
-	pts := make(plotter.XYs, len(x))
-	for i, val := range x {
-		pts[i].X = val
-		pts[i].Y = dist.Prob(val)
-	}
-
-	// Plot the PMF
-	pmf := plot.New()
-
-	pmf.Title.Text = "Binomial Distribution PMF"
-	pmf.X.Label.Text = "X"
-	pmf.Y.Label.Text = "Probability"
-
-	// Set the X-axis range from 0 to 10
-	pmf.X.Min = 0
-	pmf.X.Max = 10
-
-	// Create a line plot
-	line, _, err := plotter.NewLinePoints(pts)
-	if err != nil {
-		panic(err)
-	}
-
-	line.Color = color.NRGBA{plotColor["r"], plotColor["g"], plotColor["b"], 255}
-	pmf.Add(line)
-
-	imgCanvas := vgimg.New(vg.Points(800), vg.Points(400))
-	dc := draw.New(imgCanvas)
-
-	pmf.Draw(dc)
-
-	var pngBuffer bytes.Buffer
-
-	err = png.Encode(&pngBuffer, imgCanvas.Image())
+	// The mass lies on the integers only
+	plots, err := discretePlots("Binomial distribution", dist, integers(xMin, xMax), view, opts)
 	if err != nil {
-		log.Printf("error encoding PNG: %v\n", err)
-		return
+		return nil, nil, err
 	}
 
-	svgResponse := GnumPlotResponse{PMFPNG: pngBuffer.Bytes()}
-	jsonResponse, err := json.Marshal(svgResponse)
-	if err != nil {
-		fmt.Fprintln(w, "Error sending json:", err)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(jsonResponse)
+	return &models.BinomialResponse{
+		N:        n,
+		P:        p,
+		Mean:     dist.Mean(),
+		Variance: dist.Variance(),
+		Interval: intervalProbability(dist, view),
+	}, plots, nil
 }
 
 func Poisson(w http.ResponseWriter, r *http.Request) {
-	mu := 500.0
-	x := make([]float64, 0)
-	for i := 400.0; i < 600.0; i += 0.5 {
-		x = append(x, i)
+	serveTopic(w, r, "poisson")
+}
+
+func poissonTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	mu := q.Float("lambda", 500, 0, 1e6)
+	q.Check(mu > 0, "lambda", "must be greater than 0")
+	xMin, xMax := xRange(q, 400, 600)
+	q.Check(xMin >= 0, "xmin", "must not be negative")
+	checkSupport(q, xMin, xMax)
+	view := discreteParams(q, xMin, xMax)
+	if !q.Valid() {
+		return &models.PoissonResponse{}, nil, nil
 	}
 
-	// Define the binomial distribution
+	// Define the poisson distribution
 	dist := distuv.Poisson{
 		Lambda: mu,
 	}
 
-	// Create a plotter.XYs to hold the x, y values
-	pts := make(plotter.XYs, len(x))
-	for i, val := range x {
-		pts[i].X = val
-		pts[i].Y = dist.Prob(val)
-	}
-
-	// Plot the PMF
-	poisson := plot.New()
-
-	poisson.Title.Text = "Poisson Probability Mass Function"
-
-	// Create a line plot
-	line, err := plotter.NewLine(pts)
-	if err != nil {
-		panic(err)
-	}
-
-	line.Color = color.NRGBA{plotColor["r"], plotColor["g"], plotColor["b"], 255}
-	poisson.Add(line)
-
-	imgCanvas := vgimg.New(vg.Points(800), vg.Points(400))
-	dc := draw.New(imgCanvas)
-
-	poisson.Draw(dc)
-
-	var pngBuffer bytes.Buffer
-
-	err = png.Encode(&pngBuffer, imgCanvas.Image())
+	// The mass lies on the integers only
+	plots, err := discretePlots("Poisson distribution", dist, integers(xMin, xMax), view, opts)
 	if err != nil {
-		log.Printf("error encoding PNG: %v\n", err)
-		return
+		return nil, nil, err
 	}
 
-	svgResponse := GnumPlotResponse{PoissonPNG: pngBuffer.Bytes()}
-	jsonResponse, err := json.Marshal(svgResponse)
-	if err != nil {
-		fmt.Fprintln(w, "Error sending json:", err)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(jsonResponse)
+	return &models.PoissonResponse{
+		Lambda:   mu,
+		Mean:     dist.Mean(),
+		Variance: dist.Variance(),
+		Interval: intervalProbability(dist, view),
+	}, plots, nil
 }
 
-func deMean(x []float64) []float64 {
-	xmean := stat.Mean(x, nil)
-	result := make([]float64, len(x))
-
-	for i, xi := range x {
-		result[i] = xi - xmean
-	}
-	return result
+func CovCor(w http.ResponseWriter, r *http.Request) {
+	serveTopic(w, r, "covcor")
 }
 
-func covariance(x, y []float64) float64 {
-	n := len(x)
-	demeanX := deMean(x)
-	demeanY := deMean(y)
-	dotProduct := 0.0
-
-	for i := 0; i < n; i++ {
-		dotProduct += demeanX[i] * demeanY[i]
+func covCorTopic(q *helpers.Query, opts plotting.Options) (models.Response, []namedPlot, error) {
+	n := q.Int("n", 1000, 2, maxSampleSize)
+	seed := q.Seed()
+	if !q.Valid() {
+		return &models.CovCorResponse{}, nil, nil
 	}
-	return dotProduct / float64(n-1)
-}
 
-func correlation(x, y []float64) float64 {
-	stddevX := stat.StdDev(x, nil)
-	stddevY := stat.StdDev(y, nil)
-	return covariance(x, y) / (stddevX * stddevY)
-}
+	localRand := random.New(seed)
 
-func CovCor(w http.ResponseWriter, r *http.Request) {
 	var pageSpeeds, purchaseAmount1, purchaseAmount2 []float64
-	for i := 0; i < 1000; i++ {
-		pageSpeed := rand.NormFloat64()*1.0 + 3.0
+	for i := 0; i < n; i++ {
+		pageSpeed := localRand.NormFloat64()*1.0 + 3.0
 		pageSpeeds = append(pageSpeeds, pageSpeed)
-		purchase := rand.NormFloat64()*10.0 + 50.0
+		purchase := localRand.NormFloat64()*10.0 + 50.0
 
-		purchaseAmount1 = append(purchaseAmount1, rand.NormFloat64()*10.0+50.0)
+		purchaseAmount1 = append(purchaseAmount1, localRand.NormFloat64()*10.0+50.0)
 		purchaseAmount2 = append(purchaseAmount2, purchase/pageSpeed)
 	}
 
@@ -501,8 +335,7 @@ func CovCor(w http.ResponseWriter, r *http.Request) {
 
 	s1, err := plotter.NewScatter(pts1)
 	if err != nil {
-		fmt.Println("Error creating scatter plot:", err)
-		return
+		return nil, nil, err
 	}
 
 	p1.Add(s1)
@@ -510,20 +343,7 @@ func CovCor(w http.ResponseWriter, r *http.Request) {
 	p1.X.Label.Text = "Page Speeds"
 	p1.Y.Label.Text = "Purchase Amounts"
 
-	s1.Color = color.NRGBA{plotColor["r"], plotColor["g"], plotColor["b"], 255}
-
-	imgCanvas1 := vgimg.New(vg.Points(800), vg.Points(400))
-	dc1 := draw.New(imgCanvas1)
-
-	p1.Draw(dc1)
-
-	var pngBuffer1 bytes.Buffer
-
-	err = png.Encode(&pngBuffer1, imgCanvas1.Image())
-	if err != nil {
-		log.Printf("error encoding PNG: %v\n", err)
-		return
-	}
+	s1.Color = opts.Theme.Primary
 
 	// Scatter plot 2
 	pts2 := make(plotter.XYs, len(pageSpeeds))
@@ -536,8 +356,7 @@ func CovCor(w http.ResponseWriter, r *http.Request) {
 
 	s2, err := plotter.NewScatter(pts2)
 	if err != nil {
-		fmt.Println("Error creating scatter plot:", err)
-		return
+		return nil, nil, err
 	}
 
 	p2.Add(s2)
@@ -545,113 +364,12 @@ func CovCor(w http.ResponseWriter, r *http.Request) {
 	p2.X.Label.Text = "Page Speeds"
 	p2.Y.Label.Text = "Purchase Amounts"
 
-	s2.Color = color.NRGBA{plotColor["r"], plotColor["g"], plotColor["b"], 255}
-
-	imgCanvas2 := vgimg.New(vg.Points(800), vg.Points(400))
-	dc2 := draw.New(imgCanvas2)
-
-	p2.Draw(dc2)
-
-	var pngBuffer2 bytes.Buffer
-
-	err = png.Encode(&pngBuffer2, imgCanvas2.Image())
-	if err != nil {
-		log.Printf("error encoding PNG: %v\n", err)
-		return
-	}
-
-	covResult1 := covariance(pageSpeeds, purchaseAmount1)
-	covResult2 := covariance(pageSpeeds, purchaseAmount2)
-	correlation := correlation(pageSpeeds, purchaseAmount2)
-
-	svgResponse := GnumPlotResponse{Covariance1PNG: pngBuffer1.Bytes(), Covariance1: covResult1, Covariance2PNG: pngBuffer2.Bytes(), Covariance2: covResult2, Correlation: correlation}
-	jsonResponse, err := json.Marshal(svgResponse)
-	if err != nil {
-		fmt.Fprintln(w, "Error sending json:", err)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(jsonResponse)
-}
-
-func LinearRegression(w http.ResponseWriter, r *http.Request) {
-	// Generate 1000 random points
-	localRand := rand.New(rand.NewSource(0))
-	n := 1000
-	pageSpeeds := make([]float64, n)
-	purchaseAmount := make([]float64, n)
+	s2.Color = opts.Theme.Primary
 
-	for i := 0; i < n; i++ {
-		pageSpeeds[i] = localRand.NormFloat64()*1.0 + 3.0
-		purchaseAmount[i] = 100.0 - (pageSpeeds[i]+localRand.NormFloat64()*0.1)*3.0
-	}
-
-	// Perform linear regression
-	slope, intercept := stat.LinearRegression(pageSpeeds, purchaseAmount, nil, false)
-
-	// Calculate R-squared value
-	rSquared := stat.RSquared(pageSpeeds, purchaseAmount, nil, slope, intercept)
-	fmt.Printf("R-squared: %f\n", rSquared)
-
-	// Create a plot
-	p := plot.New()
-
-	// Create points for the scatter plot
-	points := make(plotter.XYs, n)
-	for i := range pageSpeeds {
-		points[i].X = pageSpeeds[i]
-		points[i].Y = purchaseAmount[i]
-	}
-
-	// Create a scatter plot
-	scatter, err := plotter.NewScatter(points)
-	if err != nil {
-		log.Fatal(err)
-	}
-	scatter.Color = color.NRGBA{plotColor["r"], plotColor["g"], plotColor["b"], 255}
-
-	// Add scatter plot to the plot
-	p.Add(scatter)
-
-	// Create points for the linear regression line
-	line := plotter.NewFunction(func(x float64) float64 { return intercept*x + slope })
-	line.LineStyle.Width = vg.Points(3)
-	line.Color = plotutil.Color(0)
-
-	// Add linear regression line to the plot
-	p.Add(line)
-
-	// Set plot title and labels
-	p.Title.Text = "Linear Regression on Random Points"
-	p.X.Label.Text = "X"
-	p.Y.Label.Text = "Y"
-
-	// // Save the plot to a PNG file
-	// if err := p.Save(8*vg.Inch, 4*vg.Inch, "random_linear_regression.png"); err != nil {
-	// 	log.Fatal(err)
-	// }
-
-	imgCanvas := vgimg.New(vg.Points(800), vg.Points(400))
-	dc := draw.New(imgCanvas)
-
-	p.Draw(dc)
-
-	var pngBuffer bytes.Buffer
-
-	err = png.Encode(&pngBuffer, imgCanvas.Image())
-	if err != nil {
-		log.Printf("error encoding PNG: %v\n", err)
-		return
-	}
-
-	svgResponse := GnumPlotResponse{LinearRegression: pngBuffer.Bytes(), LinearRegressionR: rSquared}
-	jsonResponse, err := json.Marshal(svgResponse)
-	if err != nil {
-		fmt.Fprintln(w, "Error sending json:", err)
-		return
-	}
+	covResult1 := stat.Covariance(pageSpeeds, purchaseAmount1, nil)
+	covResult2 := stat.Covariance(pageSpeeds, purchaseAmount2, nil)
+	correlation := stat.Correlation(pageSpeeds, purchaseAmount2, nil)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(jsonResponse)
+	svgResponse := &models.CovCorResponse{Seed: seed, Covariance1: covResult1, Covariance2: covResult2, Correlation: correlation}
+	return svgResponse, []namedPlot{{"covariance1", p1}, {"covariance2", p2}}, nil
 }
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/config"
+	"github.com/davidhalasz/gomath/cmd/web/internal/helpers"
+	"github.com/davidhalasz/gomath/cmd/web/internal/models"
+)
+
+func TestMain(m *testing.M) {
+	a := &config.AppConfig{
+		InfoLog:        log.New(io.Discard, "", 0),
+		ErrorLog:       log.New(io.Discard, "", 0),
+		MaxUploadBytes: 1 << 20,
+	}
+	NewHandlers(a)
+	helpers.NewHelpers(a)
+	os.Exit(m.Run())
+}
+
+// serve runs the named topic on the raw query string.
+func serve(name, raw string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	serveTopic(rec, httptest.NewRequest("GET", "/?"+raw, nil), name)
+	return rec
+}
+
+// Every topic must answer its defaults, so the examples in the UI work.
+func TestTopicsServeDefaults(t *testing.T) {
+	names := make([]string, 0, len(topics))
+	for name := range topics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		rec := serve(name, "seed=1")
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status %d: %s", name, rec.Code, rec.Body)
+			continue
+		}
+		var body models.Meta
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if body.Schema != topics[name].schema || len(body.Charts) == 0 {
+			t.Errorf("%s: schema %q with %d charts, want %q with some", name, body.Schema, len(body.Charts), topics[name].schema)
+		}
+	}
+}
+
+// Data the statistics are undefined for must be a 400 naming the
+// parameter, never a 500 or a response with NaN in it.
+func TestTopicsRejectInvalidQueries(t *testing.T) {
+	tests := []struct {
+		topic string
+		raw   string
+		param string
+	}{
+		{"mean", "mu=NaN", "mu"},
+		{"pdf", "sigma=1e-300", "sigma"},
+		{"t-test", "x=1,NaN,3", "x"},
+		{"chi-square-test", "test=independence&table=1,2|NaN,4", "table"},
+		{"chi-square-test", "test=goodness-of-fit&expected=0,0,0,0,0,0", "expected"},
+		{"std-deviation-variance", "n=1", "n"},
+		{"binomial", "p=0", "p"},
+		{"binomial", "p=1", "p"},
+		{"distribution/binomial", "p=0", "p"},
+		{"distribution/binomial", "p=1", "p"},
+		{"binomial", "xmin=0.2&xmax=0.8", "xmax"},
+		{"poisson", "xmin=2.1&xmax=2.9", "xmax"},
+		{"distribution/poisson", "xmin=0.2&xmax=0.8", "xmax"},
+		{"anova", "groups=1|2|3", "groups"},
+		{"t-test", "x=2,2,2", "x"},
+		{"normality", "x=0,0,0,0,0,0,0,1e-300", "x"},
+		{"logistic-regression", "n=10", "n"},
+		{"bayes", "prior=1&sensitivity=1&results=negative", "results"},
+		{"bayes", "results=positive,+", "results"},
+	}
+	for _, tt := range tests {
+		rec := serve(tt.topic, tt.raw+"&seed=1")
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s?%s: status %d, want 400: %s", tt.topic, tt.raw, rec.Code, rec.Body)
+			continue
+		}
+		var body helpers.ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Errorf("%s?%s: %v", tt.topic, tt.raw, err)
+			continue
+		}
+		found := false
+		for _, f := range body.Fields {
+			found = found || f.Param == tt.param
+		}
+		if !found {
+			t.Errorf("%s?%s: errors %v, want one for %s", tt.topic, tt.raw, body.Fields, tt.param)
+		}
+	}
+}
+
+func TestTopicChartFormats(t *testing.T) {
+	for _, format := range []string{"png", "svg"} {
+		rec := serve("mean", "seed=1&format="+format)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status %d: %s", format, rec.Code, rec.Body)
+			continue
+		}
+		want := map[string]string{"png": "image/png", "svg": "image/svg+xml"}[format]
+		if got := rec.Header().Get("Content-Type"); got != want {
+			t.Errorf("%s: Content-Type %q, want %q", format, got, want)
+		}
+		if rec.Header().Get("Cache-Control") != "public, max-age=86400" {
+			t.Errorf("%s: a seeded chart is not cacheable", format)
+		}
+	}
+}
+
+func TestDataset(t *testing.T) {
+	tests := []struct {
+		raw  string
+		body string
+		code int
+	}{
+		{"bins=3", "1,2,2,3,4", http.StatusOK},
+		// The largest value rounds past the last bin of plotter.NewHist
+		{"bins=3", "0,0.99999999999999989,1", http.StatusOK},
+		{"bins=3", "1e308,-1e308", http.StatusBadRequest},
+		{"bins=3", "1", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/?"+tt.raw, strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", "text/csv")
+		rec := httptest.NewRecorder()
+		Dataset(rec, r)
+		if rec.Code != tt.code {
+			t.Errorf("POST %q: status %d, want %d: %s", tt.body, rec.Code, tt.code, rec.Body)
+		}
+	}
+}
+
+// The discrete range includes high, so a range of one value is a sample
+// of that value.
+func TestModeRangeIsInclusive(t *testing.T) {
+	rec := serve("mode", "kind=discrete&low=5&high=5&seed=1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var body models.ModeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{5}; !reflect.DeepEqual(body.Modes, want) {
+		t.Errorf("modes = %v, want %v", body.Modes, want)
+	}
+}
+
+func TestBayesResults(t *testing.T) {
+	rec := serve("bayes", "prior=1&sensitivity=1&results=%2B,positive")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var body models.BayesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Updates) != 2 || body.Updates[0].Result != "positive" || body.Updates[1].Posterior != 1 {
+		t.Errorf("updates = %+v, want two positive results with posterior 1", body.Updates)
+	}
+	// A negative result is impossible, so its posterior is undefined
+	if body.PosteriorPositive == nil || *body.PosteriorPositive != 1 || body.PosteriorNegative != nil {
+		t.Errorf("posteriors = %v, %v; want 1 and none", body.PosteriorPositive, body.PosteriorNegative)
+	}
+}
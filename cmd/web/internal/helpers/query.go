@@ -0,0 +1,315 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/random"
+)
+
+// FieldError describes a single query parameter that failed validation.
+type FieldError struct {
+	Param   string `json:"param"`
+	Message string `json:"message"`
+}
+
+// Param documents a query parameter read through a Query. It marshals to
+// an OpenAPI schema object.
+type Param struct {
+	Name    string   `json:"-"`
+	Type    string   `json:"type"`
+	Default any      `json:"default,omitempty"`
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+	Enum    []string `json:"enum,omitempty"`
+}
+
+// Query reads typed values from a request's query string and collects
+// every validation error, so a handler can report them all at once.
+type Query struct {
+	values url.Values
+	Errors []FieldError
+	// Params lists every parameter read so far, in order.
+	Params []Param
+	// fresh is the seed made up by Seed when none was given.
+	fresh *uint64
+	// describing marks a query from DescribeQuery.
+	describing bool
+}
+
+func NewQuery(r *http.Request) *Query {
+	return &Query{values: r.URL.Query()}
+}
+
+// DescribeQuery returns an empty query that is never valid. Handlers read
+// their parameters and stop at the validity check, leaving Params filled
+// in for the API documentation.
+func DescribeQuery() *Query {
+	return &Query{values: url.Values{}, describing: true}
+}
+
+// Has reports whether the named parameter was given.
+func (q *Query) Has(name string) bool {
+	return q.values.Has(name)
+}
+
+// Int returns the named parameter, or def when it is missing.
+// Values outside [min, max] are recorded as errors.
+func (q *Query) Int(name string, def, min, max int) int {
+	lo, hi := float64(min), float64(max)
+	q.document(Param{Name: name, Type: "integer", Default: def, Minimum: &lo, Maximum: &hi})
+
+	raw := q.values.Get(name)
+	if raw == "" {
+		return def
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		q.fail(name, "must be an integer")
+		return def
+	}
+
+	if v < min || v > max {
+		q.fail(name, fmt.Sprintf("must be between %d and %d", min, max))
+		return def
+	}
+
+	return v
+}
+
+// Float returns the named parameter, or def when it is missing.
+// Values outside [min, max] are recorded as errors.
+func (q *Query) Float(name string, def, min, max float64) float64 {
+	q.document(Param{Name: name, Type: "number", Default: def, Minimum: &min, Maximum: &max})
+
+	raw := q.values.Get(name)
+	if raw == "" {
+		return def
+	}
+
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		q.fail(name, "must be a number")
+		return def
+	}
+
+	if math.IsNaN(v) || v < min || v > max {
+		q.fail(name, fmt.Sprintf("must be between %g and %g", min, max))
+		return def
+	}
+
+	return v
+}
+
+// Floats returns the named comma separated list of numbers, or def when it
+// is missing; a nil def is left undocumented. Lists with a value outside [min, max] are recorded as errors.
+func (q *Query) Floats(name string, def []float64, min, max float64) []float64 {
+	p := Param{Name: name, Type: "string"}
+	if def != nil {
+		p.Default = formatFloats(def)
+	}
+	q.document(p)
+
+	raw := q.values.Get(name)
+	if raw == "" {
+		return def
+	}
+
+	var values []float64
+	for _, field := range strings.Split(raw, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			q.fail(name, "must be a comma separated list of numbers")
+			return def
+		}
+		if math.IsNaN(v) || v < min || v > max {
+			q.fail(name, fmt.Sprintf("every value must be between %g and %g", min, max))
+			return def
+		}
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// FloatRows returns the named list of rows, separated by vertical bars, of
+// comma separated numbers, or def when it is missing. Semicolons cannot be
+// used, as url.ParseQuery rejects them. Rows may differ in
+// length; lists with a value outside [min, max] are recorded as errors.
+func (q *Query) FloatRows(name string, def [][]float64, min, max float64) [][]float64 {
+	rows := make([]string, len(def))
+	for i, row := range def {
+		rows[i] = formatFloats(row)
+	}
+	q.document(Param{Name: name, Type: "string", Default: strings.Join(rows, "|")})
+
+	raw := q.values.Get(name)
+	if raw == "" {
+		return def
+	}
+
+	var values [][]float64
+	for _, line := range strings.Split(raw, "|") {
+		var row []float64
+		for _, field := range strings.Split(line, ",") {
+			v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				q.fail(name, "must be rows of comma separated numbers separated by |")
+				return def
+			}
+			if math.IsNaN(v) || v < min || v > max {
+				q.fail(name, fmt.Sprintf("every value must be between %g and %g", min, max))
+				return def
+			}
+			row = append(row, v)
+		}
+		values = append(values, row)
+	}
+
+	return values
+}
+
+func formatFloats(values []float64) string {
+	fields := make([]string, len(values))
+	for i, v := range values {
+		fields[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return strings.Join(fields, ",")
+}
+
+// String returns the named parameter, or def when it is missing.
+func (q *Query) String(name, def string) string {
+	p := Param{Name: name, Type: "string"}
+	if def != "" {
+		p.Default = def
+	}
+	q.document(p)
+
+	if raw := q.values.Get(name); raw != "" {
+		return raw
+	}
+	return def
+}
+
+// Enum returns the named parameter, or def when it is missing.
+// Values not listed in allowed are recorded as errors.
+func (q *Query) Enum(name, def string, allowed ...string) string {
+	q.document(Param{Name: name, Type: "string", Default: def, Enum: allowed})
+
+	raw := q.values.Get(name)
+	if raw == "" {
+		return def
+	}
+
+	for _, a := range allowed {
+		if raw == a {
+			return raw
+		}
+	}
+
+	q.fail(name, fmt.Sprintf("must be one of %s", strings.Join(allowed, ", ")))
+	return def
+}
+
+// Seed returns the seed parameter, or a fresh time based seed when it is
+// missing, so the caller can always echo the seed it used.
+func (q *Query) Seed() uint64 {
+	lo, hi := 0.0, float64(random.MaxSeed)
+	q.document(Param{Name: "seed", Type: "integer", Minimum: &lo, Maximum: &hi})
+
+	if q.fresh != nil {
+		return *q.fresh
+	}
+
+	raw := q.values.Get("seed")
+	if raw == "" {
+		seed := random.NewSeed()
+		q.fresh = &seed
+		return seed
+	}
+
+	v, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || v > random.MaxSeed {
+		q.fail("seed", fmt.Sprintf("must be an integer between 0 and %d", uint64(random.MaxSeed)))
+		return 0
+	}
+
+	return v
+}
+
+// Reproducible reports whether repeating the request gives the same result,
+// which stops being true once Seed had to make up a seed.
+func (q *Query) Reproducible() bool {
+	return q.fresh == nil
+}
+
+// Values returns a copy of the query values with any made up seed filled in,
+// so a URL built from them reproduces the same result.
+func (q *Query) Values() url.Values {
+	values := make(url.Values, len(q.values)+1)
+	for k, v := range q.values {
+		values[k] = append([]string(nil), v...)
+	}
+	if q.fresh != nil {
+		values.Set("seed", strconv.FormatUint(*q.fresh, 10))
+	}
+	return values
+}
+
+// Check records message against param when ok is false, unless param
+// already has an error.
+func (q *Query) Check(ok bool, param, message string) {
+	if !ok {
+		q.fail(param, message)
+	}
+}
+
+func (q *Query) Valid() bool {
+	return len(q.Errors) == 0 && !q.describing
+}
+
+func (q *Query) document(p Param) {
+	for _, known := range q.Params {
+		if known.Name == p.Name {
+			return
+		}
+	}
+	q.Params = append(q.Params, p)
+}
+
+func (q *Query) fail(param, message string) {
+	for _, e := range q.Errors {
+		if e.Param == param {
+			return
+		}
+	}
+	q.Errors = append(q.Errors, FieldError{Param: param, Message: message})
+}
+
+// InvalidQuery sends a 400 response listing every invalid parameter.
+func InvalidQuery(w http.ResponseWriter, errs []FieldError) {
+	ErrorJSON(w, http.StatusBadRequest, "invalid query parameters", errs)
+}
+
+// ErrorResponse is the body of every JSON error.
+type ErrorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// ErrorJSON sends a structured JSON error with the given status.
+func ErrorJSON(w http.ResponseWriter, status int, message string, fields []FieldError) {
+	app.InfoLog.Println("Client error with status of", status, message, fields)
+
+	body, _ := json.Marshal(ErrorResponse{Error: message, Fields: fields})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
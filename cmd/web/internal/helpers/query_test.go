@@ -0,0 +1,169 @@
+package helpers
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func query(raw string) *Query {
+	return NewQuery(httptest.NewRequest("GET", "/?"+raw, nil))
+}
+
+func TestFloat(t *testing.T) {
+	tests := []struct {
+		raw   string
+		want  float64
+		valid bool
+	}{
+		{"", 5, true},
+		{"x=2.5", 2.5, true},
+		{"x=-10", -10, true},
+		{"x=10", 10, true},
+		{"x=10.5", 5, false},
+		{"x=abc", 5, false},
+		{"x=NaN", 5, false},
+		{"x=nan", 5, false},
+		{"x=Inf", 5, false},
+		{"x=-Inf", 5, false},
+	}
+	for _, tt := range tests {
+		q := query(tt.raw)
+		got := q.Float("x", 5, -10, 10)
+		if got != tt.want || q.Valid() != tt.valid {
+			t.Errorf("Float(%q) = %g, valid %t; want %g, valid %t", tt.raw, got, q.Valid(), tt.want, tt.valid)
+		}
+	}
+}
+
+func TestFloats(t *testing.T) {
+	def := []float64{1, 2}
+	tests := []struct {
+		raw   string
+		want  []float64
+		valid bool
+	}{
+		{"", def, true},
+		{"x=3", []float64{3}, true},
+		{"x=3,%204,5", []float64{3, 4, 5}, true},
+		{"x=3,,5", def, false},
+		{"x=3,NaN,5", def, false},
+		{"x=3,11", def, false},
+	}
+	for _, tt := range tests {
+		q := query(tt.raw)
+		got := q.Floats("x", def, -10, 10)
+		if !reflect.DeepEqual(got, tt.want) || q.Valid() != tt.valid {
+			t.Errorf("Floats(%q) = %v, valid %t; want %v, valid %t", tt.raw, got, q.Valid(), tt.want, tt.valid)
+		}
+	}
+}
+
+func TestFloatRows(t *testing.T) {
+	def := [][]float64{{1, 2}, {3}}
+	tests := []struct {
+		raw   string
+		want  [][]float64
+		valid bool
+	}{
+		{"", def, true},
+		{"x=1,2|3,4,5", [][]float64{{1, 2}, {3, 4, 5}}, true},
+		{"x=1,2|", def, false},
+		{"x=1,2|NaN,1", def, false},
+		{"x=1,2|-11", def, false},
+	}
+	for _, tt := range tests {
+		q := query(tt.raw)
+		got := q.FloatRows("x", def, -10, 10)
+		if !reflect.DeepEqual(got, tt.want) || q.Valid() != tt.valid {
+			t.Errorf("FloatRows(%q) = %v, valid %t; want %v, valid %t", tt.raw, got, q.Valid(), tt.want, tt.valid)
+		}
+	}
+}
+
+func TestInt(t *testing.T) {
+	tests := []struct {
+		raw   string
+		want  int
+		valid bool
+	}{
+		{"", 5, true},
+		{"n=1", 1, true},
+		{"n=0", 5, false},
+		{"n=1.5", 5, false},
+		{"n=101", 5, false},
+	}
+	for _, tt := range tests {
+		q := query(tt.raw)
+		got := q.Int("n", 5, 1, 100)
+		if got != tt.want || q.Valid() != tt.valid {
+			t.Errorf("Int(%q) = %d, valid %t; want %d, valid %t", tt.raw, got, q.Valid(), tt.want, tt.valid)
+		}
+	}
+}
+
+func TestEnum(t *testing.T) {
+	tests := []struct {
+		raw   string
+		want  string
+		valid bool
+	}{
+		{"", "a", true},
+		{"kind=b", "b", true},
+		{"kind=c", "a", false},
+	}
+	for _, tt := range tests {
+		q := query(tt.raw)
+		got := q.Enum("kind", "a", "a", "b")
+		if got != tt.want || q.Valid() != tt.valid {
+			t.Errorf("Enum(%q) = %q, valid %t; want %q, valid %t", tt.raw, got, q.Valid(), tt.want, tt.valid)
+		}
+	}
+}
+
+func TestSeed(t *testing.T) {
+	q := query("seed=42")
+	if got := q.Seed(); got != 42 || !q.Reproducible() {
+		t.Errorf("Seed() = %d, reproducible %t; want 42, true", got, q.Reproducible())
+	}
+
+	q = query("")
+	seed := q.Seed()
+	if q.Reproducible() {
+		t.Error("a made up seed is reproducible")
+	}
+	if again := q.Seed(); again != seed {
+		t.Errorf("Seed() changed from %d to %d", seed, again)
+	}
+	if got := q.Values().Get("seed"); got == "" {
+		t.Error("Values() leaves out the made up seed")
+	}
+
+	q = query("seed=-1")
+	q.Seed()
+	if q.Valid() {
+		t.Error("a negative seed is valid")
+	}
+}
+
+func TestCheckKeepsFirstError(t *testing.T) {
+	q := query("n=0")
+	q.Int("n", 5, 1, 100)
+	q.Check(false, "n", "second")
+	q.Check(false, "m", "other")
+	want := []FieldError{{"n", "must be between 1 and 100"}, {"m", "other"}}
+	if !reflect.DeepEqual(q.Errors, want) {
+		t.Errorf("Errors = %v, want %v", q.Errors, want)
+	}
+}
+
+func TestDescribeQueryIsNeverValid(t *testing.T) {
+	q := DescribeQuery()
+	q.Float("x", 1, 0, 2)
+	if q.Valid() {
+		t.Error("DescribeQuery is valid")
+	}
+	if len(q.Params) != 1 || q.Params[0].Name != "x" {
+		t.Errorf("Params = %v, want x documented", q.Params)
+	}
+}
@@ -0,0 +1,62 @@
+package hypothesis
+
+import (
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ANOVA is a one-way analysis of variance.
+type ANOVA struct {
+	Result
+	SSBetween float64
+	SSWithin  float64
+}
+
+// OneWayANOVA tests whether every group has the same mean. The effect size
+// is eta squared, the share of the total variation explained by the
+// groups.
+func OneWayANOVA(groups [][]float64) (ANOVA, error) {
+	if len(groups) < 2 {
+		return ANOVA{}, ErrTooFew
+	}
+
+	var all []float64
+	for _, g := range groups {
+		if len(g) == 0 {
+			return ANOVA{}, ErrTooFew
+		}
+		all = append(all, g...)
+	}
+	if len(all) <= len(groups) {
+		return ANOVA{}, ErrTooFew
+	}
+	grand := stat.Mean(all, nil)
+
+	var between, within float64
+	for _, g := range groups {
+		mean := stat.Mean(g, nil)
+		between += float64(len(g)) * (mean - grand) * (mean - grand)
+		for _, v := range g {
+			within += (v - mean) * (v - mean)
+		}
+	}
+	if within == 0 {
+		return ANOVA{}, ErrNoVariance
+	}
+
+	df1, df2 := float64(len(groups)-1), float64(len(all)-len(groups))
+	f := (between / df1) / (within / df2)
+	null := distuv.F{D1: df1, D2: df2}
+	return ANOVA{
+		Result: Result{
+			Statistic:   f,
+			DF:          []float64{df1, df2},
+			P:           null.Survival(f),
+			Alternative: Greater,
+			EffectSize:  between / (between + within),
+			Null:        null,
+		},
+		SSBetween: between,
+		SSWithin:  within,
+	}, nil
+}
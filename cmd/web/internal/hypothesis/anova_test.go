@@ -0,0 +1,44 @@
+package hypothesis
+
+import "testing"
+
+// The reference values are those of R's aov on the PlantGrowth data.
+var plantGrowth = [][]float64{
+	{4.17, 5.58, 5.18, 6.11, 4.50, 4.61, 5.17, 4.53, 5.33, 5.14},
+	{4.81, 4.17, 4.41, 3.59, 5.87, 3.83, 6.03, 4.89, 4.32, 4.69},
+	{6.31, 5.12, 5.54, 5.50, 5.37, 5.29, 4.92, 6.15, 5.80, 5.26},
+}
+
+func TestOneWayANOVA(t *testing.T) {
+	a, err := OneWayANOVA(plantGrowth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(a.Statistic, 4.846088, 1e-6) || a.DF[0] != 2 || a.DF[1] != 27 || !near(a.P, 0.01590996, 1e-6) {
+		t.Errorf("F = %g, df = %v, p = %g; want 4.846088, [2 27], 0.01590996", a.Statistic, a.DF, a.P)
+	}
+	if !near(a.SSBetween, 3.76634, 1e-6) || !near(a.SSWithin, 10.49209, 1e-6) {
+		t.Errorf("SS = %g, %g; want 3.76634, 10.49209", a.SSBetween, a.SSWithin)
+	}
+	if !near(a.EffectSize, 0.2641483, 1e-6) {
+		t.Errorf("eta squared = %g, want 0.2641483", a.EffectSize)
+	}
+}
+
+func TestOneWayANOVAErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups [][]float64
+		want   error
+	}{
+		{"one group", plantGrowth[:1], ErrTooFew},
+		{"empty group", [][]float64{{1, 2}, {}}, ErrTooFew},
+		{"single values", [][]float64{{1}, {2}, {3}}, ErrTooFew},
+		{"constant groups", [][]float64{{1, 1}, {2, 2}}, ErrNoVariance},
+	}
+	for _, tt := range tests {
+		if _, err := OneWayANOVA(tt.groups); err != tt.want {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package hypothesis
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ChiSquareGOF tests whether the observed counts follow the expected
+// proportions. The proportions are scaled to the observed total, and the
+// scaled counts are returned with the result. The effect size is Cohen's w.
+func ChiSquareGOF(observed, proportions []float64) (Result, []float64, error) {
+	if len(observed) < 2 {
+		return Result{}, nil, ErrTooFew
+	}
+	if len(observed) != len(proportions) {
+		return Result{}, nil, ErrLength
+	}
+
+	n := floats.Sum(observed)
+	total := floats.Sum(proportions)
+	// Proportions that sum to zero scale every expected count to NaN
+	if !(total > 0) {
+		return Result{}, nil, ErrEmpty
+	}
+	expected := make([]float64, len(proportions))
+	for i, p := range proportions {
+		expected[i] = p / total * n
+		if expected[i] == 0 {
+			return Result{}, nil, ErrEmpty
+		}
+	}
+
+	chi2 := stat.ChiSquare(observed, expected)
+	return chiSquareResult(chi2, float64(len(observed)-1), math.Sqrt(chi2/n)), expected, nil
+}
+
+// ChiSquareIndependence tests whether the rows and columns of a
+// contingency table are independent. The counts expected under
+// independence are returned with the result. The effect size is
+// Cramér's V.
+func ChiSquareIndependence(table [][]float64) (Result, [][]float64, error) {
+	if len(table) < 2 || len(table[0]) < 2 {
+		return Result{}, nil, ErrTooFew
+	}
+
+	rows := make([]float64, len(table))
+	cols := make([]float64, len(table[0]))
+	for i, row := range table {
+		if len(row) != len(cols) {
+			return Result{}, nil, ErrLength
+		}
+		for j, v := range row {
+			rows[i] += v
+			cols[j] += v
+		}
+	}
+	n := floats.Sum(rows)
+
+	expected := make([][]float64, len(table))
+	chi2 := 0.0
+	for i, row := range table {
+		expected[i] = make([]float64, len(row))
+		for j, v := range row {
+			expected[i][j] = rows[i] * cols[j] / n
+			if expected[i][j] == 0 {
+				return Result{}, nil, ErrEmpty
+			}
+			chi2 += (v - expected[i][j]) * (v - expected[i][j]) / expected[i][j]
+		}
+	}
+
+	k := math.Min(float64(len(rows)), float64(len(cols))) - 1
+	df := float64((len(rows) - 1) * (len(cols) - 1))
+	return chiSquareResult(chi2, df, math.Sqrt(chi2/(n*k))), expected, nil
+}
+
+// chiSquareResult builds the result of an upper tailed chi-square test.
+func chiSquareResult(chi2, df, effect float64) Result {
+	null := distuv.ChiSquared{K: df}
+	return Result{
+		Statistic:   chi2,
+		DF:          []float64{df},
+		P:           null.Survival(chi2),
+		Alternative: Greater,
+		EffectSize:  effect,
+		Null:        null,
+	}
+}
@@ -0,0 +1,62 @@
+package hypothesis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChiSquareGOF(t *testing.T) {
+	r, expected, err := ChiSquareGOF([]float64{89, 37, 30, 28, 2}, []float64{40, 20, 20, 19, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(r.Statistic, 5.794709, 1e-6) || r.DF[0] != 4 || !near(r.P, 0.2150131, 1e-6) {
+		t.Errorf("chi2 = %g, df = %g, p = %g; want 5.794709, 4, 0.2150131", r.Statistic, r.DF[0], r.P)
+	}
+	want := []float64{74.4, 37.2, 37.2, 35.34, 1.86}
+	for i := range want {
+		if !near(expected[i], want[i], 1e-12) {
+			t.Errorf("expected = %v, want %v", expected, want)
+			break
+		}
+	}
+}
+
+// The reference values are those of R's chisq.test on Agresti's table of
+// party identification by gender.
+func TestChiSquareIndependence(t *testing.T) {
+	r, expected, err := ChiSquareIndependence([][]float64{{762, 327, 468}, {484, 239, 477}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(r.Statistic, 30.07015, 1e-6) || r.DF[0] != 2 || !near(r.P, 2.953589e-07, 1e-6) {
+		t.Errorf("chi2 = %g, df = %g, p = %g; want 30.07015, 2, 2.953589e-07", r.Statistic, r.DF[0], r.P)
+	}
+	if !near(expected[0][0], 703.6714, 1e-6) {
+		t.Errorf("expected[0][0] = %g, want 703.6714", expected[0][0])
+	}
+	if !near(r.EffectSize, 0.1044358, 1e-6) {
+		t.Errorf("V = %g, want 0.1044358", r.EffectSize)
+	}
+}
+
+func TestChiSquareErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		test func() error
+		want error
+	}{
+		{"one category", func() error { _, _, err := ChiSquareGOF([]float64{3}, []float64{1}); return err }, ErrTooFew},
+		{"lengths", func() error { _, _, err := ChiSquareGOF([]float64{3, 4}, []float64{1}); return err }, ErrLength},
+		{"zero proportion", func() error { _, _, err := ChiSquareGOF([]float64{3, 4}, []float64{1, 0}); return err }, ErrEmpty},
+		{"zero proportions", func() error { _, _, err := ChiSquareGOF([]float64{3, 4}, []float64{0, 0}); return err }, ErrEmpty},
+		{"one row", func() error { _, _, err := ChiSquareIndependence([][]float64{{1, 2}}); return err }, ErrTooFew},
+		{"ragged", func() error { _, _, err := ChiSquareIndependence([][]float64{{1, 2}, {3}}); return err }, ErrLength},
+		{"empty margin", func() error { _, _, err := ChiSquareIndependence([][]float64{{1, 0}, {3, 0}}); return err }, ErrEmpty},
+	}
+	for _, tt := range tests {
+		if err := tt.test(); !reflect.DeepEqual(err, tt.want) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Package hypothesis implements classical significance tests. Every test
+// returns its statistic with the distribution the statistic follows under
+// the null hypothesis, so the p-value and the rejection region come from
+// the same distuv distribution.
+package hypothesis
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	// ErrTooFew is returned when a sample is too small for the test.
+	ErrTooFew = errors.New("hypothesis: too few observations")
+	// ErrTooMany is returned when a sample is larger than the
+	// approximations of a test hold for.
+	ErrTooMany = errors.New("hypothesis: too many observations")
+	// ErrLength is returned when paired samples differ in length.
+	ErrLength = errors.New("hypothesis: samples differ in length")
+	// ErrNoVariance is returned when the data do not vary, so the
+	// statistic is undefined.
+	ErrNoVariance = errors.New("hypothesis: the data have no variance")
+	// ErrEmpty is returned when an expected count or a margin of a table
+	// is zero.
+	ErrEmpty = errors.New("hypothesis: an expected count is zero")
+)
+
+// Alternative is the alternative hypothesis of a test.
+type Alternative string
+
+const (
+	TwoSided Alternative = "two-sided"
+	Less     Alternative = "less"
+	Greater  Alternative = "greater"
+)
+
+// Alternatives lists every alternative hypothesis.
+var Alternatives = []Alternative{TwoSided, Less, Greater}
+
+// Null is the distribution of a test statistic under the null hypothesis.
+type Null interface {
+	Prob(x float64) float64
+	CDF(x float64) float64
+	Survival(x float64) float64
+	Quantile(p float64) float64
+}
+
+// Result is the outcome of a test.
+type Result struct {
+	Statistic float64
+	// DF holds the degrees of freedom of the null distribution; F-tests
+	// have two.
+	DF          []float64
+	P           float64
+	Alternative Alternative
+	// EffectSize measures the size of the effect independently of the
+	// sample size: Cohen's d for t-tests, Cohen's w or Cramér's V for
+	// chi-square tests and eta squared for ANOVA.
+	EffectSize float64
+	Null       Null
+}
+
+// Critical returns the bounds of the rejection region at significance
+// level alpha: the null hypothesis is rejected when the statistic is at
+// most lo or at least hi. An open side is infinite.
+func (r Result) Critical(alpha float64) (lo, hi float64) {
+	switch r.Alternative {
+	case Less:
+		return r.Null.Quantile(alpha), math.Inf(1)
+	case Greater:
+		return math.Inf(-1), r.Null.Quantile(1 - alpha)
+	}
+	return r.Null.Quantile(alpha / 2), r.Null.Quantile(1 - alpha/2)
+}
+
+// Reject reports whether the null hypothesis is rejected at level alpha.
+func (r Result) Reject(alpha float64) bool {
+	return r.P <= alpha
+}
+
+// pValue returns the probability of a statistic at least as extreme as x
+// under a null distribution that is symmetric about zero. The lower tail
+// is used for both sides, as the upper tail loses precision far out.
+func pValue(null Null, x float64, alt Alternative) float64 {
+	switch alt {
+	case Less:
+		return null.CDF(x)
+	case Greater:
+		return null.CDF(-x)
+	}
+	return math.Min(1, 2*null.CDF(-math.Abs(x)))
+}
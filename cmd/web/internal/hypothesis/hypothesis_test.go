@@ -0,0 +1,41 @@
+package hypothesis
+
+import (
+	"math"
+	"testing"
+)
+
+// Student's sleep data, the extra hours of sleep of ten patients under
+// two drugs, as shipped with R.
+var (
+	sleep1 = []float64{0.7, -1.6, -0.2, -1.2, -0.1, 3.4, 3.7, 0.8, 0.0, 2.0}
+	sleep2 = []float64{1.9, 0.8, 1.1, 0.1, -0.1, 4.4, 5.5, 1.6, 4.6, 3.4}
+)
+
+// near reports whether got is within tol of want, relative to want when
+// want is not small.
+func near(got, want, tol float64) bool {
+	return got == want || math.Abs(got-want) <= tol*math.Max(1, math.Abs(want))
+}
+
+func TestCritical(t *testing.T) {
+	r, err := OneSampleT(sleep1, 0, TwoSided)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		alt    Alternative
+		lo, hi float64
+	}{
+		{TwoSided, -2.262157, 2.262157},
+		{Less, -1.833113, math.Inf(1)},
+		{Greater, math.Inf(-1), 1.833113},
+	}
+	for _, tt := range tests {
+		r.Alternative = tt.alt
+		lo, hi := r.Critical(0.05)
+		if !near(lo, tt.lo, 1e-6) || !near(hi, tt.hi, 1e-6) {
+			t.Errorf("Critical(0.05) %s = [%g, %g], want [%g, %g]", tt.alt, lo, hi, tt.lo, tt.hi)
+		}
+	}
+}
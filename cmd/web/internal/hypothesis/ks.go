@@ -0,0 +1,23 @@
+package hypothesis
+
+import (
+	"math"
+	"sort"
+)
+
+// KSDistance returns the Kolmogorov-Smirnov distance between the empirical
+// distribution of x and the distribution function cdf: the largest
+// vertical gap between the two.
+func KSDistance(x []float64, cdf func(float64) float64) float64 {
+	sorted := append([]float64(nil), x...)
+	sort.Float64s(sorted)
+
+	n := float64(len(sorted))
+	d := 0.0
+	for i, v := range sorted {
+		f := cdf(v)
+		// The empirical CDF jumps from i/n to (i+1)/n at v
+		d = math.Max(d, math.Max(float64(i+1)/n-f, f-float64(i)/n))
+	}
+	return d
+}
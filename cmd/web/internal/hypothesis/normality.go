@@ -0,0 +1,299 @@
+package hypothesis
+
+import (
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// maxShapiroWilk is the largest sample Royston's approximation of the
+// Shapiro-Wilk test holds for.
+const maxShapiroWilk = 5000
+
+// Fit is the outcome of a goodness-of-fit test. The null distributions of
+// most of these statistics have no closed form, so the p-values come from
+// published approximations and no rejection region is given.
+type Fit struct {
+	Statistic float64
+	P         float64
+}
+
+// sortedSample returns a sorted copy of x, or ErrNoVariance when its
+// values are all equal or so close that the standard deviation underflows
+// to zero.
+func sortedSample(x []float64) ([]float64, error) {
+	sorted := append([]float64(nil), x...)
+	sort.Float64s(sorted)
+	if sorted[0] == sorted[len(sorted)-1] || !(stat.StdDev(sorted, nil) > 0) {
+		return nil, ErrNoVariance
+	}
+	return sorted, nil
+}
+
+// ShapiroWilk tests whether x comes from a normal distribution, with the
+// coefficients and p-value of Royston's algorithm AS R94, for samples of
+// 3 to 5000 values. W near 1 is consistent with normality.
+func ShapiroWilk(x []float64) (Fit, error) {
+	n := len(x)
+	if n < 3 {
+		return Fit{}, ErrTooFew
+	}
+	if n > maxShapiroWilk {
+		return Fit{}, ErrTooMany
+	}
+	sorted, err := sortedSample(x)
+	if err != nil {
+		return Fit{}, err
+	}
+
+	// W is the squared correlation of the sample with the weights a,
+	// which are antisymmetric, so only the upper half is kept
+	a := shapiroWilkWeights(n)
+	num := 0.0
+	for i, ai := range a {
+		num += ai * (sorted[n-1-i] - sorted[i])
+	}
+	mean := stat.Mean(sorted, nil)
+	ss := 0.0
+	for _, v := range sorted {
+		ss += (v - mean) * (v - mean)
+	}
+	w := math.Min(num*num/ss, 1)
+
+	nf := float64(n)
+	if n == 3 {
+		// The null distribution is known exactly
+		p := 6 / math.Pi * (math.Asin(math.Sqrt(w)) - math.Pi/3)
+		return Fit{Statistic: w, P: math.Max(p, 0)}, nil
+	}
+
+	// log(1 - W) is close to normal, after one more transformation for
+	// small samples
+	y := math.Log(1 - w)
+	var m, s float64
+	if n <= 11 {
+		gamma := -2.273 + 0.459*nf
+		if y >= gamma {
+			return Fit{Statistic: w, P: 0}, nil
+		}
+		y = -math.Log(gamma - y)
+		m = poly([]float64{0.544, -0.39978, 0.025054, -6.714e-4}, nf)
+		s = math.Exp(poly([]float64{1.3822, -0.77857, 0.062767, -0.0020322}, nf))
+	} else {
+		ln := math.Log(nf)
+		m = poly([]float64{-1.5861, -0.31082, -0.083751, 0.0038915}, ln)
+		s = math.Exp(poly([]float64{-0.4803, -0.082676, 0.0030302}, ln))
+	}
+	return Fit{Statistic: w, P: distuv.Normal{Mu: m, Sigma: s}.Survival(y)}, nil
+}
+
+// shapiroWilkWeights returns the weights of the n/2 largest values of a
+// sample of n, largest first. They approximate the normalized expected
+// normal order statistics, with the two outermost corrected by Royston's
+// polynomials.
+func shapiroWilkWeights(n int) []float64 {
+	a := make([]float64, n/2)
+	if n == 3 {
+		a[0] = math.Sqrt(0.5)
+		return a
+	}
+
+	nf := float64(n)
+	m := make([]float64, n/2)
+	sum := 0.0
+	for i := range m {
+		m[i] = distuv.UnitNormal.Quantile((float64(i+1) - 0.375) / (nf + 0.25))
+		sum += m[i] * m[i]
+	}
+	sum *= 2
+	rsn := 1 / math.Sqrt(nf)
+
+	a[0] = poly([]float64{0, 0.221157, -0.147981, -2.07119, 4.434685, -2.706056}, rsn) - m[0]/math.Sqrt(sum)
+	first, rest := 1, sum-2*m[0]*m[0]
+	norm := 1 - 2*a[0]*a[0]
+	if n > 5 {
+		a[1] = poly([]float64{0, 0.042981, -0.293762, -1.752461, 5.682633, -3.582633}, rsn) - m[1]/math.Sqrt(sum)
+		first, rest = 2, rest-2*m[1]*m[1]
+		norm -= 2 * a[1] * a[1]
+	}
+	// The remaining weights are scaled so the squares of all of them sum
+	// to 1
+	scale := math.Sqrt(rest / norm)
+	for i := first; i < len(a); i++ {
+		a[i] = -m[i] / scale
+	}
+	return a
+}
+
+// poly returns the polynomial with coefficients c, constant first, at x.
+func poly(c []float64, x float64) float64 {
+	y := 0.0
+	for i := len(c) - 1; i >= 0; i-- {
+		y = y*x + c[i]
+	}
+	return y
+}
+
+// standardize returns the z-scores of the sorted sample, with the sample
+// standard deviation.
+func standardize(sorted []float64) []float64 {
+	mean, sd := stat.MeanStdDev(sorted, nil)
+	z := make([]float64, len(sorted))
+	for i, v := range sorted {
+		z[i] = (v - mean) / sd
+	}
+	return z
+}
+
+// AndersonDarling tests whether x comes from a normal distribution with
+// its mean and standard deviation estimated from x, for samples of at
+// least 8 values. The statistic weights the tails more than the
+// Kolmogorov-Smirnov distance does; the p-value is Stephens' for the
+// statistic corrected for the sample size.
+func AndersonDarling(x []float64) (Fit, error) {
+	n := len(x)
+	if n < 8 {
+		return Fit{}, ErrTooFew
+	}
+	sorted, err := sortedSample(x)
+	if err != nil {
+		return Fit{}, err
+	}
+
+	z := standardize(sorted)
+	sum := 0.0
+	for i := range z {
+		lower := math.Log(distuv.UnitNormal.CDF(z[i]))
+		upper := math.Log(distuv.UnitNormal.Survival(z[n-1-i]))
+		sum += float64(2*i+1) * (lower + upper)
+	}
+	nf := float64(n)
+	a := -nf - sum/nf
+
+	aa := a * (1 + 0.75/nf + 2.25/(nf*nf))
+	var p float64
+	switch {
+	case aa < 0.2:
+		p = 1 - math.Exp(-13.436+101.14*aa-223.73*aa*aa)
+	case aa < 0.34:
+		p = 1 - math.Exp(-8.318+42.796*aa-59.938*aa*aa)
+	case aa < 0.6:
+		p = math.Exp(0.9177 - 4.279*aa - 1.38*aa*aa)
+	case aa < 10:
+		p = math.Exp(1.2937 - 5.709*aa + 0.0186*aa*aa)
+	default:
+		p = 3.7e-24
+	}
+	return Fit{Statistic: a, P: p}, nil
+}
+
+// KolmogorovSmirnov tests whether x comes from the fully specified
+// distribution function cdf. The p-value is the asymptotic Kolmogorov
+// distribution with Stephens' correction for the sample size.
+func KolmogorovSmirnov(x []float64, cdf func(float64) float64) (Fit, error) {
+	if len(x) == 0 {
+		return Fit{}, ErrTooFew
+	}
+	d := KSDistance(x, cdf)
+	sqrtN := math.Sqrt(float64(len(x)))
+	return Fit{Statistic: d, P: kolmogorovSurvival((sqrtN + 0.12 + 0.11/sqrtN) * d)}, nil
+}
+
+// kolmogorovSurvival returns P(K > t) of the Kolmogorov distribution,
+// from whichever of its two series converges faster at t.
+func kolmogorovSurvival(t float64) float64 {
+	if t <= 0 {
+		return 1
+	}
+	if t < 1.18 {
+		sum := 0.0
+		for k := 1; k <= 10; k++ {
+			odd := float64(2*k - 1)
+			sum += math.Exp(-odd * odd * math.Pi * math.Pi / (8 * t * t))
+		}
+		return 1 - math.Sqrt(2*math.Pi)/t*sum
+	}
+	sum := 0.0
+	for k := 1; k <= 100; k++ {
+		term := math.Exp(-2 * float64(k*k) * t * t)
+		if k%2 == 0 {
+			term = -term
+		}
+		sum += term
+	}
+	return math.Max(2*sum, 0)
+}
+
+// Lilliefors tests whether x comes from a normal distribution with its
+// mean and standard deviation estimated from x, for samples of at least
+// 5 values. The statistic is the Kolmogorov-Smirnov distance to the
+// fitted normal, which is smaller than to a fixed one, so the p-value is
+// Dallal and Wilkinson's approximation instead of Kolmogorov's.
+func Lilliefors(x []float64) (Fit, error) {
+	n := len(x)
+	if n < 5 {
+		return Fit{}, ErrTooFew
+	}
+	sorted, err := sortedSample(x)
+	if err != nil {
+		return Fit{}, err
+	}
+
+	mean, sd := stat.MeanStdDev(sorted, nil)
+	d := KSDistance(sorted, distuv.Normal{Mu: mean, Sigma: sd}.CDF)
+
+	nf := float64(n)
+	dd, nd := d, nf
+	if n > 100 {
+		dd, nd = d*math.Pow(nf/100, 0.49), 100
+	}
+	p := math.Exp(-7.01256*dd*dd*(nd+2.78019) + 2.99587*dd*math.Sqrt(nd+2.78019) - 0.122119 + 0.974598/math.Sqrt(nd) + 1.67997/nd)
+	if p > 0.1 {
+		k := (math.Sqrt(nf) - 0.01 + 0.85/math.Sqrt(nf)) * d
+		switch {
+		case k <= 0.302:
+			p = 1
+		case k <= 0.5:
+			p = poly([]float64{2.76773, -19.828315, 80.709644, -138.55152, 81.218052}, k)
+		case k <= 0.9:
+			p = poly([]float64{-4.901232, 40.662806, -97.490286, 94.029866, -32.355711}, k)
+		case k <= 1.31:
+			p = poly([]float64{6.198765, -19.558097, 23.186922, -12.234627, 2.423045}, k)
+		default:
+			p = 0
+		}
+	}
+	return Fit{Statistic: d, P: p}, nil
+}
+
+// JarqueBera tests whether x comes from a normal distribution by its
+// skewness and excess kurtosis, which are both zero for normal data. The
+// statistic is asymptotically chi-square with 2 degrees of freedom, so
+// the p-value is too small for small samples.
+func JarqueBera(x []float64) (Fit, error) {
+	n := len(x)
+	if n < 3 {
+		return Fit{}, ErrTooFew
+	}
+	if _, err := sortedSample(x); err != nil {
+		return Fit{}, err
+	}
+
+	mean := stat.Mean(x, nil)
+	var m2, m3, m4 float64
+	for _, v := range x {
+		d := v - mean
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	nf := float64(n)
+	m2, m3, m4 = m2/nf, m3/nf, m4/nf
+	skew := m3 / math.Pow(m2, 1.5)
+	kurt := m4/(m2*m2) - 3
+
+	jb := nf / 6 * (skew*skew + kurt*kurt/4)
+	return Fit{Statistic: jb, P: distuv.ChiSquared{K: 2}.Survival(jb)}, nil
+}
@@ -0,0 +1,89 @@
+package hypothesis
+
+import (
+	"math"
+	"testing"
+)
+
+// weights are the weights in pounds of eleven men, the example of
+// Shapiro and Wilk's 1965 paper.
+var weights = []float64{148, 154, 158, 160, 161, 162, 166, 170, 182, 195, 236}
+
+// The Shapiro-Wilk values are those of R's shapiro.test; the others pin
+// the approximations documented on each test.
+func TestNormality(t *testing.T) {
+	var pooled []float64
+	for _, g := range plantGrowth {
+		pooled = append(pooled, g...)
+	}
+	tests := []struct {
+		name      string
+		test      func([]float64) (Fit, error)
+		x         []float64
+		statistic float64
+		p         float64
+	}{
+		{"shapiro-wilk", ShapiroWilk, weights, 0.7888147, 0.006703814},
+		{"shapiro-wilk n=3", ShapiroWilk, []float64{1, 2, 4}, 0.9642857, 0.6368868},
+		{"shapiro-wilk plants", ShapiroWilk, pooled, 0.9826830, 0.8915074},
+		{"anderson-darling", AndersonDarling, weights, 0.9467719, 0.01045402},
+		{"anderson-darling plants", AndersonDarling, pooled, 0.1506605, 0.9567459},
+		{"lilliefors", Lilliefors, weights, 0.2592154, 0.03740762},
+		{"lilliefors plants", Lilliefors, pooled, 0.09338725, 0.7241955},
+		{"jarque-bera", JarqueBera, weights, 6.982848, 0.03045747},
+		{"jarque-bera plants", JarqueBera, pooled, 0.6604168, 0.7187739},
+	}
+	for _, tt := range tests {
+		f, err := tt.test(tt.x)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !near(f.Statistic, tt.statistic, 1e-6) || !near(f.P, tt.p, 1e-6) {
+			t.Errorf("%s = %g, p = %g; want %g, %g", tt.name, f.Statistic, f.P, tt.statistic, tt.p)
+		}
+	}
+}
+
+func TestNormalityErrors(t *testing.T) {
+	constant := []float64{5, 5, 5, 5, 5, 5, 5, 5, 5, 5}
+	tiny := []float64{0, 0, 0, 0, 0, 0, 0, 0, 0, 1e-300}
+	tests := []struct {
+		name string
+		test func([]float64) (Fit, error)
+		x    []float64
+		want error
+	}{
+		{"shapiro-wilk n=2", ShapiroWilk, []float64{1, 2}, ErrTooFew},
+		{"shapiro-wilk constant", ShapiroWilk, constant, ErrNoVariance},
+		{"shapiro-wilk underflow", ShapiroWilk, tiny, ErrNoVariance},
+		{"shapiro-wilk too many", ShapiroWilk, make([]float64, maxShapiroWilk+1), ErrTooMany},
+		{"anderson-darling n=7", AndersonDarling, weights[:7], ErrTooFew},
+		{"anderson-darling constant", AndersonDarling, constant, ErrNoVariance},
+		{"anderson-darling underflow", AndersonDarling, tiny, ErrNoVariance},
+		{"lilliefors n=4", Lilliefors, weights[:4], ErrTooFew},
+		{"lilliefors constant", Lilliefors, constant, ErrNoVariance},
+		{"jarque-bera n=2", JarqueBera, weights[:2], ErrTooFew},
+		{"jarque-bera constant", JarqueBera, constant, ErrNoVariance},
+	}
+	for _, tt := range tests {
+		if _, err := tt.test(tt.x); err != tt.want {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestKolmogorovSmirnov(t *testing.T) {
+	uniform := func(x float64) float64 { return math.Max(0, math.Min(1, x)) }
+	x := []float64{0.1, 0.2, 0.3, 0.4, 0.9}
+	if d := KSDistance(x, uniform); !near(d, 0.4, 1e-12) {
+		t.Errorf("KSDistance = %g, want 0.4", d)
+	}
+	f, err := KolmogorovSmirnov(x, uniform)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Statistic != KSDistance(x, uniform) || f.P <= 0 || f.P >= 1 {
+		t.Errorf("KolmogorovSmirnov = %+v, want D = 0.4 and 0 < p < 1", f)
+	}
+}
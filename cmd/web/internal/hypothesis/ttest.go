@@ -0,0 +1,75 @@
+package hypothesis
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// OneSampleT tests whether the mean of x equals mu. The effect size is
+// Cohen's d, the distance of the mean from mu in standard deviations.
+func OneSampleT(x []float64, mu float64, alt Alternative) (Result, error) {
+	n := float64(len(x))
+	if n < 2 {
+		return Result{}, ErrTooFew
+	}
+
+	mean, sd := stat.MeanStdDev(x, nil)
+	if sd == 0 {
+		return Result{}, ErrNoVariance
+	}
+
+	t := (mean - mu) / (sd / math.Sqrt(n))
+	return tResult(t, n-1, (mean-mu)/sd, alt), nil
+}
+
+// WelchT tests whether x and y have the same mean without assuming equal
+// variances. The degrees of freedom follow the Welch-Satterthwaite
+// equation and the effect size is Cohen's d with the pooled standard
+// deviation.
+func WelchT(x, y []float64, alt Alternative) (Result, error) {
+	nx, ny := float64(len(x)), float64(len(y))
+	if nx < 2 || ny < 2 {
+		return Result{}, ErrTooFew
+	}
+
+	mx, vx := stat.MeanVariance(x, nil)
+	my, vy := stat.MeanVariance(y, nil)
+	sx, sy := vx/nx, vy/ny
+	if sx+sy == 0 {
+		return Result{}, ErrNoVariance
+	}
+
+	t := (mx - my) / math.Sqrt(sx+sy)
+	df := (sx + sy) * (sx + sy) / (sx*sx/(nx-1) + sy*sy/(ny-1))
+	pooled := math.Sqrt(((nx-1)*vx + (ny-1)*vy) / (nx + ny - 2))
+	return tResult(t, df, (mx-my)/pooled, alt), nil
+}
+
+// PairedT tests whether the mean difference of paired observations is
+// zero. It is the one-sample test of x[i] - y[i], so its effect size is
+// Cohen's d of the differences.
+func PairedT(x, y []float64, alt Alternative) (Result, error) {
+	if len(x) != len(y) {
+		return Result{}, ErrLength
+	}
+
+	diff := make([]float64, len(x))
+	for i := range x {
+		diff[i] = x[i] - y[i]
+	}
+	return OneSampleT(diff, 0, alt)
+}
+
+func tResult(t, df, d float64, alt Alternative) Result {
+	null := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: df}
+	return Result{
+		Statistic:   t,
+		DF:          []float64{df},
+		P:           pValue(null, t, alt),
+		Alternative: alt,
+		EffectSize:  d,
+		Null:        null,
+	}
+}
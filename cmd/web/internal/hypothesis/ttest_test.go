@@ -0,0 +1,60 @@
+package hypothesis
+
+import "testing"
+
+// The reference values are those of R's t.test on the sleep data.
+func TestTTests(t *testing.T) {
+	tests := []struct {
+		name      string
+		test      func() (Result, error)
+		statistic float64
+		df        float64
+		p         float64
+	}{
+		{"one sample", func() (Result, error) { return OneSampleT(sleep1, 0, TwoSided) }, 1.325710, 9, 0.2175978},
+		{"one sample greater", func() (Result, error) { return OneSampleT(sleep1, 0, Greater) }, 1.325710, 9, 0.1087989},
+		{"welch", func() (Result, error) { return WelchT(sleep1, sleep2, TwoSided) }, -1.860813, 17.77647, 0.07939414},
+		{"welch less", func() (Result, error) { return WelchT(sleep1, sleep2, Less) }, -1.860813, 17.77647, 0.03969707},
+		{"paired", func() (Result, error) { return PairedT(sleep1, sleep2, TwoSided) }, -4.062128, 9, 0.002832890},
+	}
+	for _, tt := range tests {
+		r, err := tt.test()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !near(r.Statistic, tt.statistic, 1e-6) || !near(r.DF[0], tt.df, 1e-6) || !near(r.P, tt.p, 1e-6) {
+			t.Errorf("%s: t = %g, df = %g, p = %g; want %g, %g, %g", tt.name, r.Statistic, r.DF[0], r.P, tt.statistic, tt.df, tt.p)
+		}
+	}
+}
+
+func TestWelchTEffectSize(t *testing.T) {
+	r, err := WelchT(sleep1, sleep2, TwoSided)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(r.EffectSize, -0.8321811, 1e-6) {
+		t.Errorf("d = %g, want -0.8321811", r.EffectSize)
+	}
+}
+
+func TestTTestErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		test func() (Result, error)
+		want error
+	}{
+		{"one value", func() (Result, error) { return OneSampleT([]float64{1}, 0, TwoSided) }, ErrTooFew},
+		{"constant", func() (Result, error) { return OneSampleT([]float64{2, 2, 2}, 0, TwoSided) }, ErrNoVariance},
+		{"welch one value", func() (Result, error) { return WelchT([]float64{1}, sleep2, TwoSided) }, ErrTooFew},
+		{"welch constant", func() (Result, error) { return WelchT([]float64{1, 1}, []float64{3, 3, 3}, TwoSided) }, ErrNoVariance},
+		{"paired lengths", func() (Result, error) { return PairedT(sleep1, sleep2[:9], TwoSided) }, ErrLength},
+		{"paired equal", func() (Result, error) { return PairedT(sleep1, sleep1, TwoSided) }, ErrNoVariance},
+	}
+	for _, tt := range tests {
+		if _, err := tt.test(); err != tt.want {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,554 @@
+package models
+
+// Meta is embedded in every statistics response. Schema names the response
+// type and its version, such as "mean.v1"; a new version is introduced
+// whenever a field changes meaning or is removed.
+type Meta struct {
+	Schema string            `json:"schema"`
+	Charts map[string]string `json:"charts,omitempty"`
+}
+
+// Metadata gives handlers access to the embedded Meta of any response.
+func (m *Meta) Metadata() *Meta {
+	return m
+}
+
+// Response is implemented by every statistics response through Meta.
+type Response interface {
+	Metadata() *Meta
+}
+
+type MeanResponse struct {
+	Meta
+	Seed      uint64    `json:"seed"`
+	Mean      float64   `json:"mean"`
+	Histogram Histogram `json:"histogram"`
+}
+
+type MedianResponse struct {
+	Meta
+	Seed      uint64    `json:"seed"`
+	Median    float64   `json:"median"`
+	Histogram Histogram `json:"histogram"`
+}
+
+// ModeResponse lists every mode, so ties are not hidden. For continuous
+// data the modes are the centers of the fullest histogram bins.
+type ModeResponse struct {
+	Meta
+	Seed      uint64    `json:"seed"`
+	Kind      string    `json:"kind"`
+	Modes     []float64 `json:"modes"`
+	ModeCount int       `json:"mode_count"`
+	ModeBins  []Bin     `json:"mode_bins,omitempty"`
+}
+
+type StdVarResponse struct {
+	Meta
+	Seed      uint64    `json:"seed"`
+	StdDev    float64   `json:"std_dev"`
+	Variance  float64   `json:"variance"`
+	Histogram Histogram `json:"histogram"`
+}
+
+type NormalPDFResponse struct {
+	Meta
+	Mu    float64 `json:"mu"`
+	Sigma float64 `json:"sigma"`
+}
+
+type BinomialResponse struct {
+	Meta
+	N        float64   `json:"n"`
+	P        float64   `json:"p"`
+	Mean     float64   `json:"mean"`
+	Variance float64   `json:"variance"`
+	Interval *Interval `json:"interval,omitempty"`
+}
+
+type PoissonResponse struct {
+	Meta
+	Lambda   float64   `json:"lambda"`
+	Mean     float64   `json:"mean"`
+	Variance float64   `json:"variance"`
+	Interval *Interval `json:"interval,omitempty"`
+}
+
+// Interval is the probability P(A <= X <= B) of a discrete distribution.
+type Interval struct {
+	A           int     `json:"a"`
+	B           int     `json:"b"`
+	Probability float64 `json:"probability"`
+}
+
+// DistributionResponse summarizes one distribution of the explorer. Moments
+// that are undefined for the parameters, such as the mean of Student's t
+// with nu <= 1, are left out.
+type DistributionResponse struct {
+	Meta
+	Family    string             `json:"family"`
+	Discrete  bool               `json:"discrete"`
+	Params    map[string]float64 `json:"params"`
+	Mean      *float64           `json:"mean,omitempty"`
+	Variance  *float64           `json:"variance,omitempty"`
+	StdDev    *float64           `json:"std_dev,omitempty"`
+	Skewness  *float64           `json:"skewness,omitempty"`
+	Entropy   *float64           `json:"entropy,omitempty"`
+	Quantiles []Quantile         `json:"quantiles"`
+	Interval  *Interval          `json:"interval,omitempty"`
+}
+
+// DistributionFitResponse ranks the families fitted to one sample by
+// maximum likelihood, best first. Families that could not be fitted are
+// listed in Skipped with the reason.
+type DistributionFitResponse struct {
+	Meta
+	// Seed, Source and SourceParams are left out when the values were
+	// given rather than sampled.
+	Seed         uint64             `json:"seed,omitempty"`
+	Source       string             `json:"source,omitempty"`
+	SourceParams map[string]float64 `json:"source_params,omitempty"`
+	N            int                `json:"n"`
+	Discrete     bool               `json:"discrete"`
+	Criterion    string             `json:"criterion"`
+	Fits         []DistributionFit  `json:"fits"`
+	Skipped      []SkippedFit       `json:"skipped,omitempty"`
+}
+
+// DistributionFit is one fitted family. Method is "closed-form" or
+// "numeric", and Delta is how much worse than the best fit it is by the
+// ranking criterion.
+type DistributionFit struct {
+	Rank          int                `json:"rank"`
+	Family        string             `json:"family"`
+	Params        map[string]float64 `json:"params"`
+	Method        string             `json:"method"`
+	LogLikelihood float64            `json:"log_likelihood"`
+	AIC           float64            `json:"aic"`
+	BIC           float64            `json:"bic"`
+	Delta         float64            `json:"delta"`
+}
+
+type SkippedFit struct {
+	Family string `json:"family"`
+	Reason string `json:"reason"`
+}
+
+// TimeSeriesResponse analyses one series in time order. Points where a
+// moving average or a component has no full window are null.
+type TimeSeriesResponse struct {
+	Meta
+	// Seed is left out when the values were given rather than simulated.
+	Seed uint64 `json:"seed,omitempty"`
+	N    int    `json:"n"`
+	// Times holds the times of Values in RFC 3339, and is left out when
+	// the values were given without times.
+	Times          []string              `json:"times,omitempty"`
+	Values         []float64             `json:"values"`
+	Mean           float64               `json:"mean"`
+	StdDev         float64               `json:"std_dev"`
+	MovingAverages MovingAverages        `json:"moving_averages"`
+	Correlogram    Correlogram           `json:"correlogram"`
+	Decomposition  SeasonalDecomposition `json:"decomposition"`
+}
+
+type MovingAverages struct {
+	Window int        `json:"window"`
+	Alpha  float64    `json:"alpha"`
+	SMA    []*float64 `json:"sma"`
+	WMA    []*float64 `json:"wma"`
+	EMA    []float64  `json:"ema"`
+}
+
+// Correlogram holds the autocorrelations from lag 0 and the partial
+// autocorrelations from lag 1, with the half widths of their confidence
+// bands.
+type Correlogram struct {
+	Level    float64   `json:"level"`
+	ACF      []float64 `json:"acf"`
+	ACFBand  []float64 `json:"acf_band"`
+	PACF     []float64 `json:"pacf"`
+	PACFBand float64   `json:"pacf_band"`
+}
+
+// SeasonalDecomposition holds the components of a series. Indices are the
+// seasonal effects of the positions in the period, from the first value on.
+type SeasonalDecomposition struct {
+	Model     string     `json:"model"`
+	Period    int        `json:"period"`
+	Indices   []float64  `json:"seasonal_indices"`
+	Trend     []*float64 `json:"trend"`
+	Seasonal  []float64  `json:"seasonal"`
+	Remainder []*float64 `json:"remainder"`
+}
+
+// HypothesisTestResponse is the outcome of a significance test.
+type HypothesisTestResponse struct {
+	Meta
+	Test        string    `json:"test"`
+	Alternative string    `json:"alternative"`
+	Alpha       float64   `json:"alpha"`
+	Statistic   float64   `json:"statistic"`
+	DF          []float64 `json:"df"`
+	PValue      float64   `json:"p_value"`
+	Reject      bool      `json:"reject"`
+	// CriticalValues bound the rejection region, lower bound first. One
+	// sided tests have a single bound.
+	CriticalValues []float64      `json:"critical_values"`
+	EffectSize     EffectSize     `json:"effect_size"`
+	Groups         []GroupSummary `json:"groups,omitempty"`
+	// Expected holds the counts expected under the null hypothesis of a
+	// chi-square test, one row for goodness of fit.
+	Expected  [][]float64 `json:"expected,omitempty"`
+	SSBetween float64     `json:"ss_between,omitempty"`
+	SSWithin  float64     `json:"ss_within,omitempty"`
+}
+
+type EffectSize struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+// GroupSummary describes one sample of a test. StdDev is left out for a
+// single value.
+type GroupSummary struct {
+	Name   string   `json:"name"`
+	N      int      `json:"n"`
+	Mean   float64  `json:"mean"`
+	StdDev *float64 `json:"std_dev,omitempty"`
+}
+
+// NormalityResponse holds the goodness-of-fit tests of one sample. The
+// Kolmogorov-Smirnov test compares the sample with the reference
+// distribution, the others with a normal distribution fitted to it.
+type NormalityResponse struct {
+	Meta
+	// Seed is left out when the values were given rather than sampled.
+	Seed            uint64             `json:"seed,omitempty"`
+	N               int                `json:"n"`
+	Mean            float64            `json:"mean"`
+	StdDev          float64            `json:"std_dev"`
+	Skewness        float64            `json:"skewness"`
+	ExcessKurtosis  float64            `json:"excess_kurtosis"`
+	Reference       string             `json:"reference"`
+	ReferenceParams map[string]float64 `json:"reference_params"`
+	Alpha           float64            `json:"alpha"`
+	Tests           []GoodnessOfFit    `json:"tests"`
+}
+
+type GoodnessOfFit struct {
+	Test      string  `json:"test"`
+	Statistic float64 `json:"statistic"`
+	PValue    float64 `json:"p_value"`
+	Reject    bool    `json:"reject"`
+}
+
+// ConfidenceIntervalResponse holds the interval computed from one sample
+// and the coverage of the intervals from many.
+type ConfidenceIntervalResponse struct {
+	Meta
+	Seed      uint64  `json:"seed"`
+	Parameter string  `json:"parameter"`
+	Method    string  `json:"method"`
+	Level     float64 `json:"level"`
+	N         int     `json:"n"`
+	// True is the population value the intervals should cover.
+	True     float64  `json:"true"`
+	Estimate float64  `json:"estimate"`
+	Lower    float64  `json:"lower"`
+	Upper    float64  `json:"upper"`
+	Coverage Coverage `json:"coverage"`
+}
+
+// Coverage is the share of simulated intervals that cover the true value.
+type Coverage struct {
+	Samples int     `json:"samples"`
+	Hits    int     `json:"hits"`
+	Rate    float64 `json:"rate"`
+}
+
+// CLTResponse compares the sample means of a distribution with the normal
+// distribution the central limit theorem predicts for them.
+type CLTResponse struct {
+	Meta
+	Seed            uint64             `json:"seed"`
+	Source          string             `json:"source"`
+	Params          map[string]float64 `json:"params"`
+	N               int                `json:"n"`
+	Samples         int                `json:"samples"`
+	Mean            float64            `json:"mean"`
+	StdDev          float64            `json:"std_dev"`
+	PredictedMean   float64            `json:"predicted_mean"`
+	PredictedStdDev float64            `json:"predicted_std_dev"`
+	KSDistance      float64            `json:"ks_distance"`
+}
+
+// QuantileResponse summarizes a sample by its quantiles and flags the
+// values beyond Tukey's fences as outliers.
+type QuantileResponse struct {
+	Meta
+	// Seed is left out when the values were given rather than sampled.
+	Seed      uint64            `json:"seed,omitempty"`
+	N         int               `json:"n"`
+	Kind      string            `json:"kind"`
+	Quantiles []Quantile        `json:"quantiles"`
+	Summary   FiveNumberSummary `json:"summary"`
+	IQR       float64           `json:"iqr"`
+	Fences    Bounds            `json:"fences"`
+	Outliers  []float64         `json:"outliers"`
+}
+
+type FiveNumberSummary struct {
+	Min    float64 `json:"min"`
+	Q1     float64 `json:"q1"`
+	Median float64 `json:"median"`
+	Q3     float64 `json:"q3"`
+	Max    float64 `json:"max"`
+}
+
+// BootstrapResponse holds the bootstrap distribution of a statistic with
+// the confidence intervals drawn from it.
+type BootstrapResponse struct {
+	Meta
+	Seed      uint64  `json:"seed"`
+	Statistic string  `json:"statistic"`
+	N         int     `json:"n"`
+	Resamples int     `json:"resamples"`
+	Workers   int     `json:"workers"`
+	Level     float64 `json:"level"`
+	Estimate  float64 `json:"estimate"`
+	StdError  float64 `json:"std_error"`
+	Bias      float64 `json:"bias"`
+	// Undefined counts the resamples where the statistic does not exist,
+	// which are left out of the intervals.
+	Undefined  int    `json:"undefined,omitempty"`
+	Percentile Bounds `json:"percentile"`
+	BCa        Bounds `json:"bca"`
+}
+
+type Bounds struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// PermutationTestResponse is the outcome of a permutation test of the
+// difference of a statistic between two groups.
+type PermutationTestResponse struct {
+	Meta
+	Seed        uint64         `json:"seed"`
+	Statistic   string         `json:"statistic"`
+	Alternative string         `json:"alternative"`
+	Alpha       float64        `json:"alpha"`
+	Resamples   int            `json:"resamples"`
+	Workers     int            `json:"workers"`
+	Observed    float64        `json:"observed"`
+	PValue      float64        `json:"p_value"`
+	Reject      bool           `json:"reject"`
+	Groups      []GroupSummary `json:"groups"`
+}
+
+type CovCorResponse struct {
+	Meta
+	Seed        uint64  `json:"seed"`
+	Covariance1 float64 `json:"covariance1"`
+	Covariance2 float64 `json:"covariance2"`
+	Correlation float64 `json:"correlation"`
+}
+
+// CorrelationResponse holds the covariance matrix of the columns of a
+// table and their correlation matrices. Every matrix has a row and a
+// column for each name, in order.
+type CorrelationResponse struct {
+	Meta
+	// Seed is left out when the table was given rather than sampled.
+	Seed       uint64            `json:"seed,omitempty"`
+	N          int               `json:"n"`
+	Names      []string          `json:"names"`
+	Covariance [][]float64       `json:"covariance"`
+	Pearson    CorrelationMatrix `json:"pearson"`
+	Spearman   CorrelationMatrix `json:"spearman"`
+	Kendall    CorrelationMatrix `json:"kendall"`
+}
+
+// CorrelationMatrix holds correlation coefficients with the p-values of
+// the two sided tests that they are zero.
+type CorrelationMatrix struct {
+	Coefficients [][]float64 `json:"coefficients"`
+	PValues      [][]float64 `json:"p_values"`
+}
+
+// BayesResponse applies Bayes' theorem to a test for condition A. With the
+// table input the rates are estimated from a contingency table. Updates
+// lists the posterior after each test result of the sequential mode. The
+// posterior after a result that has probability zero is undefined and left
+// out.
+type BayesResponse struct {
+	Meta
+	Input             string         `json:"input"`
+	Prior             float64        `json:"prior"`
+	Sensitivity       float64        `json:"sensitivity"`
+	Specificity       float64        `json:"specificity"`
+	Breakdown         BayesBreakdown `json:"breakdown"`
+	PosteriorPositive *float64       `json:"posterior_positive,omitempty"`
+	PosteriorNegative *float64       `json:"posterior_negative,omitempty"`
+	Updates           []BayesUpdate  `json:"updates,omitempty"`
+}
+
+// BayesBreakdown holds the joint probabilities of condition and test
+// result, and the total probability of each result.
+type BayesBreakdown struct {
+	TruePositive  float64 `json:"true_positive"`
+	FalseNegative float64 `json:"false_negative"`
+	FalsePositive float64 `json:"false_positive"`
+	TrueNegative  float64 `json:"true_negative"`
+	Positive      float64 `json:"positive"`
+	Negative      float64 `json:"negative"`
+}
+
+// BayesUpdate is one step of the sequential mode. Evidence is the total
+// probability of the result given the prior.
+type BayesUpdate struct {
+	Result    string  `json:"result"`
+	Prior     float64 `json:"prior"`
+	Evidence  float64 `json:"evidence"`
+	Posterior float64 `json:"posterior"`
+}
+
+// LinearRegressionResponse holds a simple linear fit with the t-tests of its
+// coefficients. The regression chart draws the 95% confidence and prediction
+// bands around the fitted line.
+type LinearRegressionResponse struct {
+	Meta
+	Seed             uint64      `json:"seed"`
+	Intercept        Coefficient `json:"intercept"`
+	Slope            Coefficient `json:"slope"`
+	RSquared         float64     `json:"r_squared"`
+	ResidualStdError float64     `json:"residual_std_error"`
+	DF               int         `json:"df"`
+}
+
+// PolynomialRegressionResponse holds a least squares polynomial fit.
+// Coefficients[i] multiplies x^i.
+type PolynomialRegressionResponse struct {
+	Meta
+	Seed             uint64    `json:"seed"`
+	Degree           int       `json:"degree"`
+	Coefficients     []float64 `json:"coefficients"`
+	RSquared         float64   `json:"r_squared"`
+	AdjustedRSquared float64   `json:"adjusted_r_squared"`
+}
+
+// MultipleRegressionResponse holds a least squares fit of an uploaded
+// response column on several predictor columns.
+type MultipleRegressionResponse struct {
+	Meta
+	Count            int             `json:"count"`
+	Response         string          `json:"response"`
+	Terms            []Term          `json:"terms"`
+	RSquared         float64         `json:"r_squared"`
+	AdjustedRSquared float64         `json:"adjusted_r_squared"`
+	ResidualStdError float64         `json:"residual_std_error"`
+	ANOVA            RegressionANOVA `json:"anova"`
+}
+
+// Term is one coefficient of a multiple regression. VIF, the variance
+// inflation factor, is left out for the intercept.
+type Term struct {
+	Name string `json:"name"`
+	Coefficient
+	VIF float64 `json:"vif,omitempty"`
+}
+
+// RegressionANOVA is the F-test of a regression against the intercept
+// only model.
+type RegressionANOVA struct {
+	DFModel    int     `json:"df_model"`
+	DFResidual int     `json:"df_residual"`
+	SSModel    float64 `json:"ss_model"`
+	SSResidual float64 `json:"ss_residual"`
+	F          float64 `json:"f"`
+	PValue     float64 `json:"p_value"`
+}
+
+// LogisticRegressionResponse holds a logistic fit and how well its fitted
+// probabilities classify the sample at the threshold.
+type LogisticRegressionResponse struct {
+	Meta
+	Seed            uint64          `json:"seed"`
+	Terms           []LogisticTerm  `json:"terms"`
+	LogLikelihood   float64         `json:"log_likelihood"`
+	Iterations      int             `json:"iterations"`
+	Threshold       float64         `json:"threshold"`
+	ConfusionMatrix ConfusionMatrix `json:"confusion_matrix"`
+	Accuracy        float64         `json:"accuracy"`
+	Precision       float64         `json:"precision"`
+	Recall          float64         `json:"recall"`
+	Specificity     float64         `json:"specificity"`
+	AUC             float64         `json:"auc"`
+}
+
+// LogisticTerm is one coefficient of a logistic regression with its Wald
+// test. OddsRatio is exp(Estimate).
+type LogisticTerm struct {
+	Name      string  `json:"name"`
+	Estimate  float64 `json:"estimate"`
+	StdError  float64 `json:"std_error"`
+	ZStat     float64 `json:"z_stat"`
+	PValue    float64 `json:"p_value"`
+	OddsRatio float64 `json:"odds_ratio"`
+}
+
+type ConfusionMatrix struct {
+	TruePositives  int `json:"true_positives"`
+	FalsePositives int `json:"false_positives"`
+	TrueNegatives  int `json:"true_negatives"`
+	FalseNegatives int `json:"false_negatives"`
+}
+
+// DatasetResponse describes an uploaded dataset.
+type DatasetResponse struct {
+	Meta
+	Count     int        `json:"count"`
+	Mean      float64    `json:"mean"`
+	Median    float64    `json:"median"`
+	Modes     []float64  `json:"modes"`
+	ModeCount int        `json:"mode_count"`
+	ModeBins  []Bin      `json:"mode_bins,omitempty"`
+	Variance  float64    `json:"variance"`
+	StdDev    float64    `json:"std_dev"`
+	Quantiles []Quantile `json:"quantiles"`
+}
+
+// Histogram describes how a sample was binned for its chart, and the
+// kernel density estimate drawn over it, if any.
+type Histogram struct {
+	Binning   string  `json:"binning"`
+	Bins      int     `json:"bins"`
+	BinWidth  float64 `json:"bin_width"`
+	Kernel    string  `json:"kernel,omitempty"`
+	Bandwidth float64 `json:"bandwidth,omitempty"`
+}
+
+// Bin is a histogram bin holding one of the modes of continuous data.
+type Bin struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+// Coefficient is a regression coefficient with the two sided t-test of the
+// hypothesis that it is zero.
+type Coefficient struct {
+	Estimate float64 `json:"estimate"`
+	StdError float64 `json:"std_error"`
+	TStat    float64 `json:"t_stat"`
+	PValue   float64 `json:"p_value"`
+}
+
+// Quantile is the value below which the share P of a distribution lies.
+// Value is null where it overflows, as in the far tail of a heavy tailed
+// distribution.
+type Quantile struct {
+	P     float64  `json:"p"`
+	Value *float64 `json:"value"`
+}
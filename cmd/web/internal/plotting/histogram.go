@@ -0,0 +1,78 @@
+package plotting
+
+import (
+	"errors"
+	"image/color"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/plot/plotter"
+)
+
+// NewHist returns a histogram of values in n bins of equal width, like
+// plotter.NewHist. plotter.NewHist panics when rounding puts a value just
+// below the maximum past the last bin, which happens with computed values
+// such as bootstrap replicates; here such a value goes into the last bin.
+func NewHist(values []float64, n int) (*plotter.Histogram, error) {
+	if n <= 0 {
+		return nil, errors.New("plotting: histogram with non-positive number of bins")
+	}
+	if len(values) == 0 {
+		return nil, errors.New("plotting: histogram of no values")
+	}
+
+	lo, hi := floats.Min(values), floats.Max(values)
+	if hi <= lo {
+		n = 1
+	}
+	width := (hi - lo) / float64(n)
+	if width == 0 {
+		width = 1
+	}
+
+	bins := make([]plotter.HistogramBin, n)
+	for i := range bins {
+		bins[i].Min = lo + float64(i)*width
+		bins[i].Max = lo + float64(i+1)*width
+	}
+	for _, v := range values {
+		bins[min(max(int((v-lo)/width), 0), n-1)].Weight++
+	}
+
+	return &plotter.Histogram{
+		Bins:      bins,
+		Width:     width,
+		FillColor: color.Gray{128},
+		LineStyle: plotter.DefaultLineStyle,
+	}, nil
+}
+
+// NewLatticeHist returns a histogram of values that lie on a grid of the
+// given spacing, such as counts or means of counts, with one bin centered
+// on every grid point from the smallest value to the largest.
+func NewLatticeHist(values []float64, spacing float64) (*plotter.Histogram, error) {
+	if !(spacing > 0) {
+		return nil, errors.New("plotting: histogram with non-positive lattice spacing")
+	}
+	if len(values) == 0 {
+		return nil, errors.New("plotting: histogram of no values")
+	}
+
+	lo, hi := floats.Min(values), floats.Max(values)
+	bins := make([]plotter.HistogramBin, int(math.Round((hi-lo)/spacing))+1)
+	for i := range bins {
+		center := lo + float64(i)*spacing
+		bins[i].Min = center - spacing/2
+		bins[i].Max = center + spacing/2
+	}
+	for _, v := range values {
+		bins[int(math.Round((v-lo)/spacing))].Weight++
+	}
+
+	return &plotter.Histogram{
+		Bins:      bins,
+		Width:     spacing,
+		FillColor: color.Gray{128},
+		LineStyle: plotter.DefaultLineStyle,
+	}, nil
+}
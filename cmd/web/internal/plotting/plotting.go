@@ -0,0 +1,193 @@
+// Package plotting renders gonum plots into encoded images, so every chart
+// shares one size, theme and encoder configuration.
+package plotting
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"sort"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgeps"
+	"gonum.org/v1/plot/vg/vgimg"
+	"gonum.org/v1/plot/vg/vgpdf"
+	"gonum.org/v1/plot/vg/vgsvg"
+)
+
+// Format is an image encoding produced by Render.
+type Format string
+
+const (
+	PNG Format = "png"
+	SVG Format = "svg"
+	PDF Format = "pdf"
+	EPS Format = "eps"
+)
+
+// Formats lists every format Render supports.
+var Formats = []Format{PNG, SVG, PDF, EPS}
+
+// ContentType returns the MIME type of images encoded in f.
+func (f Format) ContentType() string {
+	switch f {
+	case SVG:
+		return "image/svg+xml"
+	case PDF:
+		return "application/pdf"
+	case EPS:
+		return "application/postscript"
+	}
+	return "image/png"
+}
+
+// Theme holds the colors shared by every chart.
+type Theme struct {
+	// Primary colors the main series: bars, lines and points.
+	Primary color.Color
+	// Highlight colors the parts a chart calls out, such as the modes.
+	Highlight color.Color
+	// Background fills the canvas behind the plot.
+	Background color.Color
+	// Foreground colors the title, axes, ticks and legend.
+	Foreground color.Color
+	// Series colors lines drawn together that must be told apart, such as
+	// competing fits, in order.
+	Series []color.Color
+}
+
+// SeriesColor returns the color of the i-th of several series, repeating
+// the series colors when there are more series than colors.
+func (t Theme) SeriesColor(i int) color.Color {
+	if len(t.Series) == 0 {
+		return t.Primary
+	}
+	return t.Series[i%len(t.Series)]
+}
+
+// Themes are the themes a chart can be rendered with, by name.
+var Themes = map[string]Theme{
+	"light": {
+		Primary:    color.NRGBA{71, 85, 105, 255},
+		Highlight:  color.NRGBA{217, 119, 6, 255},
+		Background: color.White,
+		Foreground: color.Black,
+		Series: []color.Color{
+			color.NRGBA{217, 119, 6, 255},
+			color.NRGBA{37, 99, 235, 255},
+			color.NRGBA{5, 150, 105, 255},
+			color.NRGBA{225, 29, 72, 255},
+			color.NRGBA{124, 58, 237, 255},
+			color.NRGBA{8, 145, 178, 255},
+		},
+	},
+	"dark": {
+		Primary:    color.NRGBA{203, 213, 225, 255},
+		Highlight:  color.NRGBA{245, 158, 11, 255},
+		Background: color.NRGBA{15, 23, 42, 255},
+		Foreground: color.NRGBA{226, 232, 240, 255},
+		Series: []color.Color{
+			color.NRGBA{245, 158, 11, 255},
+			color.NRGBA{96, 165, 250, 255},
+			color.NRGBA{52, 211, 153, 255},
+			color.NRGBA{251, 113, 133, 255},
+			color.NRGBA{167, 139, 250, 255},
+			color.NRGBA{34, 211, 238, 255},
+		},
+	},
+}
+
+// Translucent returns c with its opacity scaled by alpha in [0, 1], for
+// shaded areas that must not hide what is drawn beneath them.
+func Translucent(c color.Color, alpha float64) color.Color {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	n.A = uint8(float64(n.A) * alpha)
+	return n
+}
+
+// ThemeNames returns the names of the available themes in sorted order.
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Options control how a plot is rendered.
+type Options struct {
+	Width  vg.Length
+	Height vg.Length
+	// DPI is the resolution of PNG images; vector formats ignore it.
+	DPI    int
+	Theme  Theme
+	Format Format
+}
+
+// DefaultOptions returns an 800x400 point PNG in the light theme.
+func DefaultOptions() Options {
+	return Options{
+		Width:  vg.Points(800),
+		Height: vg.Points(400),
+		DPI:    vgimg.DefaultDPI,
+		Theme:  Themes["light"],
+		Format: PNG,
+	}
+}
+
+// Render draws p with the given options and returns the encoded image.
+// The theme's background and foreground colors are applied to p; series
+// colors are chosen by the caller when the plotters are built.
+func Render(p *plot.Plot, opts Options) ([]byte, error) {
+	applyTheme(p, opts.Theme)
+
+	var c vg.CanvasWriterTo
+
+	switch opts.Format {
+	case PNG:
+		img := vgimg.NewWith(
+			vgimg.UseWH(opts.Width, opts.Height),
+			vgimg.UseDPI(opts.DPI),
+			vgimg.UseBackgroundColor(opts.Theme.Background),
+		)
+		c = vgimg.PngCanvas{Canvas: img}
+	case SVG:
+		c = vgsvg.New(opts.Width, opts.Height)
+	case PDF:
+		c = vgpdf.New(opts.Width, opts.Height)
+	case EPS:
+		c = vgeps.New(opts.Width, opts.Height)
+	default:
+		return nil, fmt.Errorf("plotting: unsupported format %q", opts.Format)
+	}
+
+	p.Draw(draw.New(c))
+
+	var buf bytes.Buffer
+	if _, err := c.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("plotting: encoding %s: %w", opts.Format, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func applyTheme(p *plot.Plot, t Theme) {
+	if t.Background != nil {
+		p.BackgroundColor = t.Background
+	}
+
+	if t.Foreground == nil {
+		return
+	}
+
+	p.Title.TextStyle.Color = t.Foreground
+	p.Legend.TextStyle.Color = t.Foreground
+	for _, a := range []*plot.Axis{&p.X, &p.Y} {
+		a.Label.TextStyle.Color = t.Foreground
+		a.LineStyle.Color = t.Foreground
+		a.Tick.Label.Color = t.Foreground
+		a.Tick.LineStyle.Color = t.Foreground
+	}
+}
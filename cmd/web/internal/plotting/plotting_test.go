@@ -0,0 +1,194 @@
+package plotting
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	_ "image/png"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+func testPlot(t *testing.T) *plot.Plot {
+	t.Helper()
+	p := plot.New()
+	p.Title.Text = "test"
+	line, err := plotter.NewLine(plotter.XYs{{X: 0, Y: 0}, {X: 1, Y: 2}, {X: 2, Y: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Add(line)
+	return p
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		format Format
+		magic  string
+	}{
+		{PNG, "\x89PNG"},
+		{SVG, "<?xml"},
+		{PDF, "%PDF"},
+		{EPS, "%%!PS-Adobe"}, // vgeps doubles the percent sign
+	}
+	for _, tt := range tests {
+		opts := DefaultOptions()
+		opts.Format = tt.format
+		got, err := Render(testPlot(t), opts)
+		if err != nil {
+			t.Errorf("Render(%s): %v", tt.format, err)
+			continue
+		}
+		if !bytes.HasPrefix(got, []byte(tt.magic)) {
+			t.Errorf("Render(%s) starts with %q, want %q", tt.format, got[:min(len(got), 8)], tt.magic)
+		}
+	}
+}
+
+func TestRenderSize(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Width, opts.Height, opts.DPI = vg.Points(300), vg.Points(200), 72
+	got, err := Render(testPlot(t), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(got))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Width != 300 || cfg.Height != 200 {
+		t.Errorf("PNG is %dx%d, want 300x200", cfg.Width, cfg.Height)
+	}
+}
+
+func TestRenderUnsupportedFormat(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Format = "gif"
+	if _, err := Render(testPlot(t), opts); err == nil {
+		t.Error("Render(gif) succeeded")
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{PNG, "image/png"},
+		{SVG, "image/svg+xml"},
+		{PDF, "application/pdf"},
+		{EPS, "application/postscript"},
+	}
+	for _, tt := range tests {
+		if got := tt.format.ContentType(); got != tt.want {
+			t.Errorf("%s.ContentType() = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestSeriesColor(t *testing.T) {
+	light := Themes["light"]
+	n := len(light.Series)
+	if got := light.SeriesColor(n + 1); got != light.Series[1] {
+		t.Errorf("SeriesColor(%d) = %v, want %v", n+1, got, light.Series[1])
+	}
+	plain := Theme{Primary: color.Black}
+	if got := plain.SeriesColor(3); got != color.Black {
+		t.Errorf("SeriesColor without series = %v, want the primary color", got)
+	}
+}
+
+func TestThemeNames(t *testing.T) {
+	if got, want := ThemeNames(), []string{"dark", "light"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ThemeNames() = %v, want %v", got, want)
+	}
+}
+
+func TestTranslucent(t *testing.T) {
+	got := Translucent(color.NRGBA{10, 20, 30, 200}, 0.5)
+	if want := (color.NRGBA{10, 20, 30, 100}); got != want {
+		t.Errorf("Translucent = %v, want %v", got, want)
+	}
+}
+
+func weights(h *plotter.Histogram) []float64 {
+	w := make([]float64, len(h.Bins))
+	for i, b := range h.Bins {
+		w[i] = b.Weight
+	}
+	return w
+}
+
+func TestNewHist(t *testing.T) {
+	tests := []struct {
+		values []float64
+		n      int
+		want   []float64
+	}{
+		{[]float64{0, 1, 2, 3, 4}, 2, []float64{2, 3}},
+		{[]float64{0, 0.5, 1}, 4, []float64{1, 0, 1, 1}},
+		{[]float64{3, 3, 3}, 5, []float64{3}},
+		{[]float64{7}, 1, []float64{1}},
+	}
+	for _, tt := range tests {
+		h, err := NewHist(tt.values, tt.n)
+		if err != nil {
+			t.Errorf("NewHist(%v, %d): %v", tt.values, tt.n, err)
+			continue
+		}
+		if got := weights(h); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("NewHist(%v, %d) = %v, want %v", tt.values, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestNewHistErrors(t *testing.T) {
+	if _, err := NewHist([]float64{1, 2}, 0); err == nil {
+		t.Error("NewHist with no bins succeeded")
+	}
+	if _, err := NewHist(nil, 3); err == nil {
+		t.Error("NewHist of no values succeeded")
+	}
+}
+
+func TestNewLatticeHist(t *testing.T) {
+	h, err := NewLatticeHist([]float64{1, 1.5, 1.5, 3}, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := weights(h), []float64{1, 2, 0, 0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("weights = %v, want %v", got, want)
+	}
+	if h.Bins[0].Min != 0.75 || h.Bins[4].Max != 3.25 {
+		t.Errorf("bins span [%g, %g], want [0.75, 3.25]", h.Bins[0].Min, h.Bins[4].Max)
+	}
+
+	for _, spacing := range []float64{0, -1} {
+		if _, err := NewLatticeHist([]float64{1}, spacing); err == nil {
+			t.Errorf("NewLatticeHist with spacing %g succeeded", spacing)
+		}
+	}
+	if _, err := NewLatticeHist(nil, 1); err == nil {
+		t.Error("NewLatticeHist of no values succeeded")
+	}
+}
+
+func TestStemsRangeIncludesZero(t *testing.T) {
+	s, err := NewStems(plotter.XYs{{X: 1, Y: 2}, {X: 2, Y: 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ymin, ymax := s.DataRange(); ymin != 0 || ymax != 5 {
+		t.Errorf("DataRange y = [%g, %g], want [0, 5]", ymin, ymax)
+	}
+}
+
+func TestNewViolinNeedsTwoPoints(t *testing.T) {
+	if _, err := NewViolin(vg.Points(20), 0, plotter.XYs{{X: 0, Y: 1}}); err == nil {
+		t.Error("NewViolin with one density point succeeded")
+	}
+}
@@ -0,0 +1,79 @@
+package plotting
+
+import (
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// Stems draws a line from zero up to every point, topped with a glyph. It
+// is the usual chart of a probability mass function, which has no value
+// between its points.
+type Stems struct {
+	plotter.XYs
+	draw.LineStyle
+	draw.GlyphStyle
+}
+
+// NewStems returns stems for a copy of the points in xys.
+func NewStems(xys plotter.XYer) (*Stems, error) {
+	data, err := plotter.CopyXYs(xys)
+	if err != nil {
+		return nil, err
+	}
+	return &Stems{
+		XYs:        data,
+		LineStyle:  plotter.DefaultLineStyle,
+		GlyphStyle: plotter.DefaultGlyphStyle,
+	}, nil
+}
+
+// Plot implements the plot.Plotter interface.
+func (s *Stems) Plot(c draw.Canvas, p *plot.Plot) {
+	trX, trY := p.Transforms(&c)
+	base := trY(0)
+	for _, pt := range s.XYs {
+		top := vg.Point{X: trX(pt.X), Y: trY(pt.Y)}
+		c.StrokeLines(s.LineStyle, c.ClipLinesXY([]vg.Point{{X: top.X, Y: base}, top})...)
+		if c.Contains(top) {
+			c.DrawGlyph(s.GlyphStyle, top)
+		}
+	}
+}
+
+// DataRange implements the plot.DataRanger interface. The range always
+// includes zero, where the stems start.
+func (s *Stems) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin, xmax, ymin, ymax = plotter.XYRange(s)
+	if ymin > 0 {
+		ymin = 0
+	}
+	if ymax < 0 {
+		ymax = 0
+	}
+	return xmin, xmax, ymin, ymax
+}
+
+// GlyphBoxes implements the plot.GlyphBoxer interface, so the glyphs at
+// the edges are not cut off.
+func (s *Stems) GlyphBoxes(p *plot.Plot) []plot.GlyphBox {
+	boxes := make([]plot.GlyphBox, len(s.XYs))
+	for i, pt := range s.XYs {
+		boxes[i].X = p.X.Norm(pt.X)
+		boxes[i].Y = p.Y.Norm(pt.Y)
+		r := s.GlyphStyle.Radius
+		boxes[i].Rectangle = vg.Rectangle{
+			Min: vg.Point{X: -r, Y: -r},
+			Max: vg.Point{X: r, Y: r},
+		}
+	}
+	return boxes
+}
+
+// Thumbnail implements the plot.Thumbnailer interface.
+func (s *Stems) Thumbnail(c *draw.Canvas) {
+	x := c.Center().X
+	c.StrokeLine2(s.LineStyle, x, c.Min.Y, x, c.Max.Y)
+	c.DrawGlyph(s.GlyphStyle, vg.Point{X: x, Y: c.Max.Y})
+}
@@ -0,0 +1,91 @@
+package plotting
+
+import (
+	"errors"
+	"image/color"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// Violin draws a density estimate mirrored about a vertical axis, so the
+// shape of a distribution can be compared with its box plot. Each point
+// of Density holds a value in X and its density in Y.
+type Violin struct {
+	Density plotter.XYs
+	// Location is the x position of the axis of the violin.
+	Location float64
+	// Width is the width of the violin where the density is highest.
+	Width     vg.Length
+	FillColor color.Color
+	draw.LineStyle
+}
+
+// NewViolin returns a violin of width w at loc for a copy of the density
+// points, which must be sorted by value.
+func NewViolin(w vg.Length, loc float64, density plotter.XYer) (*Violin, error) {
+	data, err := plotter.CopyXYs(density)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2 {
+		return nil, errors.New("plotting: violin needs at least 2 density points")
+	}
+	return &Violin{
+		Density:   data,
+		Location:  loc,
+		Width:     w,
+		LineStyle: plotter.DefaultLineStyle,
+	}, nil
+}
+
+// Plot implements the plot.Plotter interface.
+func (v *Violin) Plot(c draw.Canvas, p *plot.Plot) {
+	trX, trY := p.Transforms(&c)
+	x := trX(v.Location)
+
+	top := 0.0
+	for _, pt := range v.Density {
+		top = max(top, pt.Y)
+	}
+	if top == 0 {
+		return
+	}
+
+	// Trace the right side upwards and the left side back down
+	outline := make([]vg.Point, 0, 2*len(v.Density)+1)
+	for _, pt := range v.Density {
+		half := v.Width / 2 * vg.Length(pt.Y/top)
+		outline = append(outline, vg.Point{X: x + half, Y: trY(pt.X)})
+	}
+	for i := len(v.Density) - 1; i >= 0; i-- {
+		half := v.Width / 2 * vg.Length(v.Density[i].Y/top)
+		outline = append(outline, vg.Point{X: x - half, Y: trY(v.Density[i].X)})
+	}
+	outline = append(outline, outline[0])
+
+	if v.FillColor != nil {
+		c.FillPolygon(v.FillColor, c.ClipPolygonY(outline))
+	}
+	c.StrokeLines(v.LineStyle, c.ClipLinesY(outline)...)
+}
+
+// DataRange implements the plot.DataRanger interface.
+func (v *Violin) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return v.Location, v.Location, v.Density[0].X, v.Density[len(v.Density)-1].X
+}
+
+// GlyphBoxes implements the plot.GlyphBoxer interface, so the violin is
+// not cut off at the sides.
+func (v *Violin) GlyphBoxes(p *plot.Plot) []plot.GlyphBox {
+	return []plot.GlyphBox{{
+		X: p.X.Norm(v.Location),
+		Y: p.Y.Norm(v.Density[0].X),
+		Rectangle: vg.Rectangle{
+			Min: vg.Point{X: -v.Width / 2},
+			Max: vg.Point{X: v.Width / 2},
+		},
+	}}
+}
@@ -0,0 +1,23 @@
+package random
+
+import (
+	"time"
+
+	"golang.org/x/exp/rand"
+)
+
+// MaxSeed is the largest accepted seed. Seeds stay below 2^53 so they
+// survive a round trip through a JavaScript number unchanged.
+const MaxSeed = 1<<53 - 1
+
+// New returns a generator seeded with seed. Every sampling handler goes
+// through it, so the same seed always reproduces the same numbers.
+// The generator also implements rand.Source for the distuv types.
+func New(seed uint64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// NewSeed returns a seed derived from the current time.
+func NewSeed() uint64 {
+	return uint64(time.Now().UnixNano()) & MaxSeed
+}
@@ -0,0 +1,27 @@
+package random
+
+import "testing"
+
+func TestNewIsReproducible(t *testing.T) {
+	a, b := New(42), New(42)
+	for i := 0; i < 10; i++ {
+		if x, y := a.Uint64(), b.Uint64(); x != y {
+			t.Fatalf("draw %d: %d != %d with the same seed", i, x, y)
+		}
+	}
+	if New(1).Uint64() == New(2).Uint64() {
+		t.Error("seeds 1 and 2 give the same first draw")
+	}
+}
+
+func TestNewSeed(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if s := NewSeed(); s > MaxSeed {
+			t.Fatalf("NewSeed() = %d, above MaxSeed", s)
+		}
+	}
+	// MaxSeed survives a float64 round trip
+	if uint64(float64(MaxSeed)) != MaxSeed {
+		t.Error("MaxSeed is not exact as a float64")
+	}
+}
@@ -0,0 +1,120 @@
+package regression
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Coefficient is an estimated model coefficient with the two sided t-test
+// of the hypothesis that it is zero.
+type Coefficient struct {
+	Estimate float64
+	StdErr   float64
+	T        float64
+	P        float64
+}
+
+// Linear is an ordinary least squares fit of y = intercept + slope*x.
+type Linear struct {
+	Intercept Coefficient
+	Slope     Coefficient
+	RSquared  float64
+	// ResidualStdErr estimates the standard deviation of the errors.
+	ResidualStdErr float64
+	// DF is the residual degrees of freedom, n - 2.
+	DF int
+
+	n     int
+	meanX float64
+	sxx   float64
+}
+
+// FitLinear fits a simple linear regression and the standard errors of its
+// coefficients under the usual assumption of independent normal errors.
+func FitLinear(x, y []float64) (Linear, error) {
+	if len(x) != len(y) {
+		return Linear{}, errors.New("regression: x and y have different lengths")
+	}
+	n := len(x)
+	if n < 3 {
+		return Linear{}, errors.New("regression: a linear fit needs at least 3 points")
+	}
+
+	meanX := stat.Mean(x, nil)
+	var sxx float64
+	for _, xi := range x {
+		sxx += (xi - meanX) * (xi - meanX)
+	}
+	if sxx == 0 {
+		return Linear{}, errors.New("regression: x has no variance")
+	}
+
+	// gonum returns the intercept first
+	alpha, beta := stat.LinearRegression(x, y, nil, false)
+
+	var ssRes float64
+	for i, xi := range x {
+		r := y[i] - (alpha + beta*xi)
+		ssRes += r * r
+	}
+
+	df := n - 2
+	s := math.Sqrt(ssRes / float64(df))
+	l := Linear{
+		RSquared:       stat.RSquared(x, y, nil, alpha, beta),
+		ResidualStdErr: s,
+		DF:             df,
+		n:              n,
+		meanX:          meanX,
+		sxx:            sxx,
+	}
+	l.Intercept = coefficient(alpha, s*math.Sqrt(1/float64(n)+meanX*meanX/sxx), df)
+	l.Slope = coefficient(beta, s/math.Sqrt(sxx), df)
+
+	return l, nil
+}
+
+// Predict returns the fitted value at x.
+func (l Linear) Predict(x float64) float64 {
+	return l.Intercept.Estimate + l.Slope.Estimate*x
+}
+
+// ConfidenceBand returns the bounds of the level confidence interval for the
+// mean response at x.
+func (l Linear) ConfidenceBand(x, level float64) (lo, hi float64) {
+	return l.band(x, level, 0)
+}
+
+// PredictionBand returns the bounds of the level prediction interval for a
+// new observation at x, which also covers the scatter of the errors.
+func (l Linear) PredictionBand(x, level float64) (lo, hi float64) {
+	return l.band(x, level, 1)
+}
+
+func (l Linear) band(x, level, extra float64) (lo, hi float64) {
+	t := criticalT(level, l.DF)
+	d := x - l.meanX
+	half := t * l.ResidualStdErr * math.Sqrt(extra+1/float64(l.n)+d*d/l.sxx)
+	y := l.Predict(x)
+	return y - half, y + half
+}
+
+// coefficient tests estimate against zero with df degrees of freedom.
+func coefficient(estimate, stdErr float64, df int) Coefficient {
+	c := Coefficient{Estimate: estimate, StdErr: stdErr}
+	if stdErr == 0 {
+		// t is undefined for a perfect fit, so T and P stay zero
+		return c
+	}
+	c.T = estimate / stdErr
+	c.P = 2 * distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(df)}.Survival(math.Abs(c.T))
+	return c
+}
+
+// criticalT returns the two sided critical value of Student's t.
+func criticalT(level float64, df int) float64 {
+	return distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(df)}.Quantile(1 - (1-level)/2)
+}
@@ -0,0 +1,83 @@
+package regression
+
+import "testing"
+
+// The reference values are those of R's summary(lm(dist ~ speed, cars)).
+func TestFitLinear(t *testing.T) {
+	l, err := FitLinear(speed, dist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"intercept", l.Intercept.Estimate, -17.579095},
+		{"intercept SE", l.Intercept.StdErr, 6.758440},
+		{"intercept t", l.Intercept.T, -2.601058},
+		{"intercept p", l.Intercept.P, 0.01231882},
+		{"slope", l.Slope.Estimate, 3.932409},
+		{"slope SE", l.Slope.StdErr, 0.4155128},
+		{"slope t", l.Slope.T, 9.463990},
+		{"slope p", l.Slope.P, 1.489836e-12},
+		{"R squared", l.RSquared, 0.6510794},
+		{"residual SE", l.ResidualStdErr, 15.37959},
+	}
+	for _, tt := range tests {
+		if !near(tt.got, tt.want, 1e-6) {
+			t.Errorf("%s = %g, want %g", tt.name, tt.got, tt.want)
+		}
+	}
+	if l.DF != 48 {
+		t.Errorf("DF = %d, want 48", l.DF)
+	}
+}
+
+// At a speed of 21 the standard error of the mean response is
+// s sqrt(1/50 + (21 - 15.4)^2 / 1370) = 3.185128 on 48 degrees of freedom.
+func TestLinearBands(t *testing.T) {
+	l, err := FitLinear(speed, dist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Predict(21); !near(got, 65.00149, 1e-6) {
+		t.Errorf("Predict(21) = %g, want 65.00149", got)
+	}
+	lo, hi := l.ConfidenceBand(21, 0.95)
+	if !near(lo, 58.59738, 1e-6) || !near(hi, 71.40559, 1e-6) {
+		t.Errorf("ConfidenceBand(21) = [%g, %g], want [58.59738, 71.40559]", lo, hi)
+	}
+	plo, phi := l.PredictionBand(21, 0.95)
+	if !(plo < lo && phi > hi) || !near(phi-l.Predict(21), l.Predict(21)-plo, 1e-9) {
+		t.Errorf("PredictionBand(21) = [%g, %g] does not contain the confidence band symmetrically", plo, phi)
+	}
+}
+
+func TestFitLinearPerfectFit(t *testing.T) {
+	l, err := FitLinear([]float64{1, 2, 3, 4}, []float64{3, 5, 7, 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(l.Intercept.Estimate, 1, 1e-12) || !near(l.Slope.Estimate, 2, 1e-12) || !near(l.RSquared, 1, 1e-12) {
+		t.Errorf("fit = %g + %g x, R squared %g; want 1 + 2 x, 1", l.Intercept.Estimate, l.Slope.Estimate, l.RSquared)
+	}
+	if l.Slope.T != 0 || l.Slope.P != 0 {
+		t.Errorf("slope t = %g, p = %g; want both left at zero", l.Slope.T, l.Slope.P)
+	}
+}
+
+func TestFitLinearErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y []float64
+	}{
+		{"lengths", []float64{1, 2, 3}, []float64{1, 2}},
+		{"two points", []float64{1, 2}, []float64{1, 2}},
+		{"constant x", []float64{2, 2, 2}, []float64{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		if _, err := FitLinear(tt.x, tt.y); err == nil {
+			t.Errorf("%s: FitLinear succeeded", tt.name)
+		}
+	}
+}
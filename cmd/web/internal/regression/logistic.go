@@ -0,0 +1,146 @@
+package regression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Limits of the Newton iterations of FitLogistic.
+const (
+	maxLogisticIterations = 100
+	logisticTolerance     = 1e-10
+)
+
+// ErrNotConverged is returned when the logistic fit does not settle, which
+// usually means that the predictors separate the two classes perfectly.
+var ErrNotConverged = errors.New("regression: logistic fit did not converge, the classes may be perfectly separated")
+
+// Logistic is a maximum likelihood fit of
+// P(y = 1) = 1 / (1 + exp(-(b0 + b1*x1 + ... + bp*xp))).
+type Logistic struct {
+	// Coefficients holds the intercept followed by one coefficient per
+	// predictor. T holds the Wald z statistic and P its normal p-value.
+	Coefficients []Coefficient
+	// Probabilities holds the fitted P(y = 1) of every observation.
+	Probabilities []float64
+	LogLikelihood float64
+	Iterations    int
+}
+
+// FitLogistic fits a logistic regression of the 0/1 outcomes y on the
+// columns of predictors, adding an intercept. Newton's method is run as
+// iteratively reweighted least squares: every step solves a weighted least
+// squares problem with a QR factorization.
+func FitLogistic(predictors [][]float64, y []float64) (Logistic, error) {
+	n, p := len(y), len(predictors)
+	if p == 0 {
+		return Logistic{}, errors.New("regression: at least one predictor is needed")
+	}
+	for _, x := range predictors {
+		if len(x) != n {
+			return Logistic{}, errors.New("regression: predictors and y have different lengths")
+		}
+	}
+	k := p + 1
+	if n <= k {
+		return Logistic{}, fmt.Errorf("regression: %d predictors need more than %d observations", p, k)
+	}
+	for _, v := range y {
+		if v != 0 && v != 1 {
+			return Logistic{}, errors.New("regression: logistic outcomes must be 0 or 1")
+		}
+	}
+
+	x := design(predictors, n)
+	beta := make([]float64, k)
+	weighted := mat.NewDense(n, k, nil)
+	target := make([]float64, n)
+
+	for iteration := 1; iteration <= maxLogisticIterations; iteration++ {
+		// Scale the rows by sqrt(w) so that ordinary least squares solves
+		// the weighted problem for the working response z
+		for i := 0; i < n; i++ {
+			eta := mat.Dot(x.RowView(i), mat.NewVecDense(k, beta))
+			pi := sigmoid(eta)
+
+			w := pi * (1 - pi)
+			if w < 1e-12 {
+				return Logistic{}, ErrNotConverged
+			}
+			sw := math.Sqrt(w)
+			for j := 0; j < k; j++ {
+				weighted.Set(i, j, sw*x.At(i, j))
+			}
+			target[i] = sw * (eta + (y[i]-pi)/w)
+		}
+
+		next, qr, err := leastSquares(weighted, target)
+		if err != nil {
+			return Logistic{}, ErrCollinear
+		}
+
+		change := 0.0
+		for j := range beta {
+			change = math.Max(change, math.Abs(next[j]-beta[j]))
+		}
+		beta = next
+
+		if change < logisticTolerance*(1+maxAbs(beta)) {
+			return logisticResult(x, y, beta, qr, iteration)
+		}
+	}
+
+	return Logistic{}, ErrNotConverged
+}
+
+// logisticResult computes the Wald tests and fitted probabilities of the
+// converged coefficients. The standard errors come from the inverse Fisher
+// information (X'WX)^-1 of the last weighted factorization.
+func logisticResult(x *mat.Dense, y, beta []float64, qr *mat.QR, iterations int) (Logistic, error) {
+	n, k := x.Dims()
+
+	stdErrs, err := unscaledStdErrs(qr, k)
+	if err != nil {
+		return Logistic{}, err
+	}
+
+	l := Logistic{
+		Coefficients:  make([]Coefficient, k),
+		Probabilities: make([]float64, n),
+		Iterations:    iterations,
+	}
+	for j := range beta {
+		c := Coefficient{Estimate: beta[j], StdErr: stdErrs[j]}
+		c.T = c.Estimate / c.StdErr
+		c.P = 2 * distuv.UnitNormal.CDF(-math.Abs(c.T))
+		l.Coefficients[j] = c
+	}
+
+	for i := 0; i < n; i++ {
+		pi := sigmoid(mat.Dot(x.RowView(i), mat.NewVecDense(k, beta)))
+		l.Probabilities[i] = pi
+		if y[i] == 1 {
+			l.LogLikelihood += math.Log(pi)
+		} else {
+			l.LogLikelihood += math.Log(1 - pi)
+		}
+	}
+
+	return l, nil
+}
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+func maxAbs(x []float64) float64 {
+	m := 0.0
+	for _, v := range x {
+		m = math.Max(m, math.Abs(v))
+	}
+	return m
+}
@@ -0,0 +1,61 @@
+package regression
+
+import (
+	"math"
+	"testing"
+)
+
+// With one binary predictor the logistic fit reproduces the log odds of
+// each group: 3 of 4 successes at x = 0 and 1 of 4 at x = 1 give an
+// intercept of ln 3 and a slope of -2 ln 3, with standard errors
+// sqrt(1/3 + 1) and sqrt(2 (1/3 + 1)).
+func TestFitLogistic(t *testing.T) {
+	x := []float64{0, 0, 0, 0, 1, 1, 1, 1}
+	y := []float64{1, 1, 1, 0, 1, 0, 0, 0}
+	l, err := FitLogistic([][]float64{x}, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"intercept", l.Coefficients[0].Estimate, math.Log(3)},
+		{"intercept SE", l.Coefficients[0].StdErr, math.Sqrt(4.0 / 3)},
+		{"slope", l.Coefficients[1].Estimate, -2 * math.Log(3)},
+		{"slope SE", l.Coefficients[1].StdErr, math.Sqrt(8.0 / 3)},
+		{"slope z", l.Coefficients[1].T, -2 * math.Log(3) / math.Sqrt(8.0/3)},
+		{"log likelihood", l.LogLikelihood, 2 * (3*math.Log(0.75) + math.Log(0.25))},
+		{"P(y = 1 | x = 0)", l.Probabilities[0], 0.75},
+		{"P(y = 1 | x = 1)", l.Probabilities[7], 0.25},
+	}
+	for _, tt := range tests {
+		if !near(tt.got, tt.want, 1e-8) {
+			t.Errorf("%s = %g, want %g", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestFitLogisticErrors(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5, 6}
+	tests := []struct {
+		name       string
+		predictors [][]float64
+		y          []float64
+		want       error
+	}{
+		{"separated", [][]float64{x}, []float64{0, 0, 0, 1, 1, 1}, ErrNotConverged},
+		{"one class", [][]float64{x}, []float64{1, 1, 1, 1, 1, 1}, ErrNotConverged},
+		{"collinear", [][]float64{x, x}, []float64{0, 1, 0, 1, 1, 0}, ErrCollinear},
+		{"outcome not 0/1", [][]float64{x}, []float64{0, 1, 2, 1, 0, 1}, nil},
+		{"no predictors", nil, []float64{0, 1, 0, 1, 0, 1}, nil},
+		{"lengths", [][]float64{x[:5]}, []float64{0, 1, 0, 1, 0, 1}, nil},
+		{"too few", [][]float64{x[:2]}, []float64{0, 1}, nil},
+	}
+	for _, tt := range tests {
+		_, err := FitLogistic(tt.predictors, tt.y)
+		if err == nil || tt.want != nil && err != tt.want {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
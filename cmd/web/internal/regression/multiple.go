@@ -0,0 +1,184 @@
+package regression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ErrCollinear is returned when a predictor is a linear combination of the
+// others, so the coefficients are not identifiable.
+var ErrCollinear = errors.New("regression: predictors are collinear")
+
+// Multiple is an ordinary least squares fit of
+// y = b0 + b1*x1 + ... + bp*xp.
+type Multiple struct {
+	// Coefficients holds the intercept followed by one coefficient per
+	// predictor.
+	Coefficients []Coefficient
+	// VIF holds the variance inflation factor of each predictor.
+	VIF              []float64
+	RSquared         float64
+	AdjustedRSquared float64
+	ResidualStdErr   float64
+	ANOVA            ANOVA
+	// Fitted holds the fitted value of every observation.
+	Fitted []float64
+}
+
+// ANOVA is the F-test of the hypothesis that every predictor coefficient
+// is zero.
+type ANOVA struct {
+	DFModel    int
+	DFResidual int
+	SSModel    float64
+	SSResidual float64
+	F          float64
+	P          float64
+}
+
+// FitMultiple regresses y on the columns of predictors, adding an intercept.
+func FitMultiple(predictors [][]float64, y []float64) (Multiple, error) {
+	n, p := len(y), len(predictors)
+	if p == 0 {
+		return Multiple{}, errors.New("regression: at least one predictor is needed")
+	}
+	for _, x := range predictors {
+		if len(x) != n {
+			return Multiple{}, errors.New("regression: predictors and y have different lengths")
+		}
+	}
+	k := p + 1
+	if n <= k {
+		return Multiple{}, fmt.Errorf("regression: %d predictors need more than %d observations", p, k)
+	}
+
+	beta, qr, err := leastSquares(design(predictors, n), y)
+	if err != nil {
+		return Multiple{}, ErrCollinear
+	}
+
+	m := Multiple{Fitted: make([]float64, n)}
+	mean := stat.Mean(y, nil)
+	var ssRes, ssTot float64
+	for i := range y {
+		m.Fitted[i] = beta[0]
+		for j, x := range predictors {
+			m.Fitted[i] += beta[j+1] * x[i]
+		}
+		r := y[i] - m.Fitted[i]
+		ssRes += r * r
+		ssTot += (y[i] - mean) * (y[i] - mean)
+	}
+	if ssTot == 0 {
+		return Multiple{}, errors.New("regression: y has no variance")
+	}
+
+	dfRes := n - k
+	m.RSquared = 1 - ssRes/ssTot
+	m.AdjustedRSquared = 1 - (1-m.RSquared)*float64(n-1)/float64(dfRes)
+	m.ResidualStdErr = math.Sqrt(ssRes / float64(dfRes))
+
+	stdErrs, err := unscaledStdErrs(qr, k)
+	if err != nil {
+		return Multiple{}, err
+	}
+	m.Coefficients = make([]Coefficient, k)
+	for j := range beta {
+		m.Coefficients[j] = coefficient(beta[j], m.ResidualStdErr*stdErrs[j], dfRes)
+	}
+
+	m.ANOVA = ANOVA{
+		DFModel:    p,
+		DFResidual: dfRes,
+		SSModel:    ssTot - ssRes,
+		SSResidual: ssRes,
+	}
+	if ssRes > 0 {
+		m.ANOVA.F = (m.ANOVA.SSModel / float64(p)) / (ssRes / float64(dfRes))
+		m.ANOVA.P = distuv.F{D1: float64(p), D2: float64(dfRes)}.Survival(m.ANOVA.F)
+	}
+
+	m.VIF, err = vif(predictors, n)
+	if err != nil {
+		return Multiple{}, err
+	}
+
+	return m, nil
+}
+
+// vif regresses each predictor on the others: VIF_j = 1 / (1 - R_j^2).
+func vif(predictors [][]float64, n int) ([]float64, error) {
+	result := make([]float64, len(predictors))
+	for j, x := range predictors {
+		if len(predictors) == 1 {
+			result[j] = 1
+			continue
+		}
+
+		others := make([][]float64, 0, len(predictors)-1)
+		others = append(others, predictors[:j]...)
+		others = append(others, predictors[j+1:]...)
+
+		beta, _, err := leastSquares(design(others, n), x)
+		if err != nil {
+			return nil, ErrCollinear
+		}
+
+		mean := stat.Mean(x, nil)
+		var ssRes, ssTot float64
+		for i := range x {
+			fitted := beta[0]
+			for l, o := range others {
+				fitted += beta[l+1] * o[i]
+			}
+			ssRes += (x[i] - fitted) * (x[i] - fitted)
+			ssTot += (x[i] - mean) * (x[i] - mean)
+		}
+		if ssTot == 0 || ssRes == 0 {
+			// A constant predictor duplicates the intercept
+			return nil, ErrCollinear
+		}
+		result[j] = ssTot / ssRes
+	}
+	return result, nil
+}
+
+// unscaledStdErrs returns the square roots of the diagonal of (X'X)^-1 for
+// the k column design X factorized by qr. Since X = QR, (X'X)^-1 is
+// R^-1 R^-T and its diagonal holds the squared row norms of R^-1.
+func unscaledStdErrs(qr *mat.QR, k int) ([]float64, error) {
+	var r mat.Dense
+	qr.RTo(&r)
+	upper := mat.NewTriDense(k, mat.Upper, nil)
+	upper.Copy(r.Slice(0, k, 0, k))
+
+	var rInv mat.TriDense
+	if err := rInv.InverseTri(upper); err != nil {
+		return nil, ErrCollinear
+	}
+
+	result := make([]float64, k)
+	for j := range result {
+		row := mat.Row(nil, j, &rInv)
+		result[j] = math.Sqrt(floats.Dot(row, row))
+	}
+	return result, nil
+}
+
+// design returns the n by p+1 design matrix with a leading intercept column.
+func design(predictors [][]float64, n int) *mat.Dense {
+	d := mat.NewDense(n, len(predictors)+1, nil)
+	for i := 0; i < n; i++ {
+		d.Set(i, 0, 1)
+		for j, x := range predictors {
+			d.Set(i, j+1, x[i])
+		}
+	}
+	return d
+}
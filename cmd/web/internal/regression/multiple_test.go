@@ -0,0 +1,93 @@
+package regression
+
+import "testing"
+
+// A multiple regression on one predictor is the simple linear one, with F
+// the square of the slope's t.
+func TestFitMultipleOnePredictor(t *testing.T) {
+	m, err := FitMultiple([][]float64{speed}, dist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := FitLinear(speed, dist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"intercept", m.Coefficients[0].Estimate, l.Intercept.Estimate},
+		{"intercept SE", m.Coefficients[0].StdErr, l.Intercept.StdErr},
+		{"slope", m.Coefficients[1].Estimate, l.Slope.Estimate},
+		{"slope SE", m.Coefficients[1].StdErr, l.Slope.StdErr},
+		{"R squared", m.RSquared, 0.6510794},
+		{"adjusted R squared", m.AdjustedRSquared, 0.6438102},
+		{"F", m.ANOVA.F, 89.56711},
+		{"F p", m.ANOVA.P, 1.489836e-12},
+		{"VIF", m.VIF[0], 1},
+	}
+	for _, tt := range tests {
+		if !near(tt.got, tt.want, 1e-6) {
+			t.Errorf("%s = %g, want %g", tt.name, tt.got, tt.want)
+		}
+	}
+	if m.ANOVA.DFModel != 1 || m.ANOVA.DFResidual != 48 {
+		t.Errorf("DF = %d, %d; want 1, 48", m.ANOVA.DFModel, m.ANOVA.DFResidual)
+	}
+}
+
+func TestFitMultipleExact(t *testing.T) {
+	x1 := []float64{1, 2, 3, 4, 5, 6}
+	x2 := []float64{2, 1, 4, 3, 6, 5}
+	y := make([]float64, len(x1))
+	for i := range y {
+		y[i] = 1 + 2*x1[i] - 3*x2[i]
+	}
+	m, err := FitMultiple([][]float64{x1, x2}, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j, want := range []float64{1, 2, -3} {
+		if !near(m.Coefficients[j].Estimate, want, 1e-9) {
+			t.Errorf("coefficient %d = %g, want %g", j, m.Coefficients[j].Estimate, want)
+		}
+	}
+	for i := range y {
+		if !near(m.Fitted[i], y[i], 1e-9) {
+			t.Errorf("Fitted = %v, want %v", m.Fitted, y)
+			break
+		}
+	}
+	// x1 and x2 have a correlation of 0.8285714, so both factors are
+	// 1 / (1 - 0.8285714^2)
+	for _, v := range m.VIF {
+		if !near(v, 3.190104, 1e-6) {
+			t.Errorf("VIF = %v, want 3.190104 twice", m.VIF)
+			break
+		}
+	}
+}
+
+func TestFitMultipleErrors(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5}
+	y := []float64{2, 1, 4, 3, 6}
+	tests := []struct {
+		name       string
+		predictors [][]float64
+		y          []float64
+		want       error
+	}{
+		{"no predictors", nil, y, nil},
+		{"lengths", [][]float64{x[:4]}, y, nil},
+		{"too few", [][]float64{x[:2]}, y[:2], nil},
+		{"constant y", [][]float64{x}, []float64{1, 1, 1, 1, 1}, nil},
+		{"collinear", [][]float64{x, {2, 4, 6, 8, 10}}, y, ErrCollinear},
+	}
+	for _, tt := range tests {
+		_, err := FitMultiple(tt.predictors, tt.y)
+		if err == nil || tt.want != nil && err != tt.want {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
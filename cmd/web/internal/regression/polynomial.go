@@ -0,0 +1,93 @@
+// Package regression fits regression models with gonum's linear algebra.
+package regression
+
+import (
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Polynomial is a least squares polynomial fit.
+type Polynomial struct {
+	// Coefficients[i] multiplies x^i.
+	Coefficients     []float64
+	RSquared         float64
+	AdjustedRSquared float64
+}
+
+// Eval returns the value of the fitted polynomial at x.
+func (p Polynomial) Eval(x float64) float64 {
+	y := 0.0
+	for i := len(p.Coefficients) - 1; i >= 0; i-- {
+		y = y*x + p.Coefficients[i]
+	}
+	return y
+}
+
+// FitPolynomial fits y = b0 + b1*x + ... + bd*x^d by least squares on the
+// Vandermonde matrix of x.
+func FitPolynomial(x, y []float64, degree int) (Polynomial, error) {
+	if len(x) != len(y) {
+		return Polynomial{}, errors.New("regression: x and y have different lengths")
+	}
+	if degree < 1 {
+		return Polynomial{}, errors.New("regression: degree must be at least 1")
+	}
+
+	n, k := len(x), degree+1
+	if n <= k {
+		return Polynomial{}, fmt.Errorf("regression: a degree %d fit needs more than %d points", degree, k)
+	}
+
+	design := mat.NewDense(n, k, nil)
+	for i, xi := range x {
+		v := 1.0
+		for j := 0; j < k; j++ {
+			design.Set(i, j, v)
+			v *= xi
+		}
+	}
+
+	beta, _, err := leastSquares(design, y)
+	if err != nil {
+		return Polynomial{}, err
+	}
+
+	p := Polynomial{Coefficients: beta}
+
+	mean := stat.Mean(y, nil)
+	var ssRes, ssTot float64
+	for i, xi := range x {
+		r := y[i] - p.Eval(xi)
+		ssRes += r * r
+		ssTot += (y[i] - mean) * (y[i] - mean)
+	}
+	if ssTot == 0 {
+		return Polynomial{}, errors.New("regression: y has no variance")
+	}
+
+	p.RSquared = 1 - ssRes/ssTot
+	p.AdjustedRSquared = 1 - (1-p.RSquared)*float64(n-1)/float64(n-k)
+
+	return p, nil
+}
+
+// leastSquares solves design*beta = y in the least squares sense. A QR
+// factorization is used rather than the normal equations, which keeps
+// badly conditioned designs numerically stable; the factorization is
+// returned for computing standard errors.
+func leastSquares(design *mat.Dense, y []float64) ([]float64, *mat.QR, error) {
+	n, _ := design.Dims()
+
+	var qr mat.QR
+	qr.Factorize(design)
+
+	var beta mat.Dense
+	if err := qr.SolveTo(&beta, false, mat.NewDense(n, 1, y)); err != nil {
+		return nil, nil, fmt.Errorf("regression: %w", err)
+	}
+
+	return mat.Col(nil, 0, &beta), &qr, nil
+}
@@ -0,0 +1,56 @@
+package regression
+
+import "testing"
+
+func TestFitPolynomial(t *testing.T) {
+	x := []float64{-2, -1, 0, 1, 2, 3}
+	y := make([]float64, len(x))
+	for i, xi := range x {
+		y[i] = 1 - 2*xi + 0.5*xi*xi
+	}
+	p, err := FitPolynomial(x, y, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []float64{1, -2, 0.5} {
+		if !near(p.Coefficients[i], want, 1e-9) {
+			t.Errorf("Coefficients = %v, want [1 -2 0.5]", p.Coefficients)
+			break
+		}
+	}
+	if !near(p.RSquared, 1, 1e-12) || !near(p.Eval(4), 1, 1e-9) {
+		t.Errorf("R squared = %g, Eval(4) = %g; want 1, 1", p.RSquared, p.Eval(4))
+	}
+}
+
+// A degree 1 polynomial is the simple linear fit.
+func TestFitPolynomialLinear(t *testing.T) {
+	p, err := FitPolynomial(speed, dist, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(p.Coefficients[0], -17.579095, 1e-6) || !near(p.Coefficients[1], 3.932409, 1e-6) {
+		t.Errorf("Coefficients = %v, want [-17.579095 3.932409]", p.Coefficients)
+	}
+	if !near(p.AdjustedRSquared, 0.6438102, 1e-6) {
+		t.Errorf("AdjustedRSquared = %g, want 0.6438102", p.AdjustedRSquared)
+	}
+}
+
+func TestFitPolynomialErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		x, y   []float64
+		degree int
+	}{
+		{"lengths", []float64{1, 2, 3, 4}, []float64{1, 2, 3}, 1},
+		{"degree 0", []float64{1, 2, 3, 4}, []float64{1, 2, 3, 4}, 0},
+		{"too few", []float64{1, 2, 3}, []float64{1, 4, 9}, 2},
+		{"constant y", []float64{1, 2, 3, 4}, []float64{5, 5, 5, 5}, 1},
+	}
+	for _, tt := range tests {
+		if _, err := FitPolynomial(tt.x, tt.y, tt.degree); err == nil {
+			t.Errorf("%s: FitPolynomial succeeded", tt.name)
+		}
+	}
+}
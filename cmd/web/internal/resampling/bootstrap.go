@@ -0,0 +1,157 @@
+package resampling
+
+import (
+	"math"
+	"sort"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/estimation"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// normal gives the z-scores of the BCa interval.
+var normal = distuv.UnitNormal
+
+// Bootstrap is the distribution of a statistic over resamples of the data
+// drawn with replacement.
+type Bootstrap struct {
+	Statistic Statistic
+	// Estimate is the statistic of the original data.
+	Estimate float64
+	// Replicates holds the statistic of every resample where it is
+	// defined, in ascending order.
+	Replicates []float64
+	// Undefined counts the resamples where the statistic is NaN, such as
+	// a correlation of rows that were all drawn from one.
+	Undefined int
+
+	data [][]float64
+}
+
+// NewBootstrap resamples the rows of data o.Resamples times and evaluates
+// s on each resample.
+func NewBootstrap(s Statistic, data [][]float64, o Options) (Bootstrap, error) {
+	n, err := columnsLen(s, data)
+	if err != nil {
+		return Bootstrap{}, err
+	}
+	if n < 2 {
+		return Bootstrap{}, ErrTooFew
+	}
+	estimate := s.Eval(clone(data))
+	if math.IsNaN(estimate) {
+		return Bootstrap{}, ErrUndefined
+	}
+
+	replicates := run(o, func() func(rnd *rand.Rand) float64 {
+		resample := clone(data)
+		return func(rnd *rand.Rand) float64 {
+			for i := 0; i < n; i++ {
+				row := rnd.Intn(n)
+				for j, c := range data {
+					resample[j][i] = c[row]
+				}
+			}
+			return s.Eval(resample)
+		}
+	})
+
+	b := Bootstrap{Statistic: s, Estimate: estimate, data: data}
+	for _, r := range replicates {
+		if math.IsNaN(r) {
+			b.Undefined++
+			continue
+		}
+		b.Replicates = append(b.Replicates, r)
+	}
+	if len(b.Replicates) == 0 {
+		return Bootstrap{}, ErrUndefined
+	}
+	sort.Float64s(b.Replicates)
+	return b, nil
+}
+
+// StdError returns the standard deviation of the replicates.
+func (b Bootstrap) StdError() float64 {
+	return stat.StdDev(b.Replicates, nil)
+}
+
+// Bias returns the mean of the replicates less the estimate.
+func (b Bootstrap) Bias() float64 {
+	return stat.Mean(b.Replicates, nil) - b.Estimate
+}
+
+// Percentile returns the interval between the (1-level)/2 and (1+level)/2
+// quantiles of the replicates.
+func (b Bootstrap) Percentile(level float64) estimation.Interval {
+	alpha := (1 - level) / 2
+	return b.interval(alpha, 1-alpha)
+}
+
+// BCa returns the bias corrected and accelerated interval. It shifts the
+// percentiles by the median bias of the replicates and by the acceleration,
+// the skewness of the jackknife estimates, so it suits statistics whose
+// bootstrap distribution is skewed or off center.
+func (b Bootstrap) BCa(level float64) (estimation.Interval, error) {
+	below, equal := 0, 0
+	for _, r := range b.Replicates {
+		if r < b.Estimate {
+			below++
+		} else if r == b.Estimate {
+			equal++
+		}
+	}
+	share := (float64(below) + float64(equal)/2) / float64(len(b.Replicates))
+	if share == 0 || share == 1 {
+		return estimation.Interval{}, ErrDegenerate
+	}
+	z0 := normal.Quantile(share)
+	a := b.acceleration()
+
+	adjust := func(p float64) float64 {
+		z := z0 + normal.Quantile(p)
+		return normal.CDF(z0 + z/(1-a*z))
+	}
+	alpha := (1 - level) / 2
+	return b.interval(adjust(alpha), adjust(1-alpha)), nil
+}
+
+// acceleration returns the acceleration of the BCa interval from the
+// jackknife estimates, the statistic of the data with each row left out
+// in turn. It is zero when the jackknife estimates do not vary.
+func (b Bootstrap) acceleration() float64 {
+	n := len(b.data[0])
+	jackknife := make([]float64, 0, n)
+	rest := make([][]float64, len(b.data))
+	for i := 0; i < n; i++ {
+		for j, c := range b.data {
+			rest[j] = append(append(rest[j][:0], c[:i]...), c[i+1:]...)
+		}
+		if v := b.Statistic.Eval(rest); !math.IsNaN(v) {
+			jackknife = append(jackknife, v)
+		}
+	}
+
+	mean := stat.Mean(jackknife, nil)
+	num, den := 0.0, 0.0
+	for _, v := range jackknife {
+		d := mean - v
+		num += d * d * d
+		den += d * d
+	}
+	if den == 0 {
+		return 0
+	}
+	return num / (6 * math.Pow(den, 1.5))
+}
+
+// interval returns the estimate with the lo and hi quantiles of the
+// replicates.
+func (b Bootstrap) interval(lo, hi float64) estimation.Interval {
+	return estimation.Interval{
+		Estimate: b.Estimate,
+		Lower:    stat.Quantile(lo, stat.Empirical, b.Replicates, nil),
+		Upper:    stat.Quantile(hi, stat.Empirical, b.Replicates, nil),
+	}
+}
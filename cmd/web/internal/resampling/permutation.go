@@ -0,0 +1,83 @@
+package resampling
+
+import (
+	"math"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/hypothesis"
+	"golang.org/x/exp/rand"
+)
+
+// Permutation is a permutation test of the hypothesis that two groups come
+// from the same population. Under it the group labels are exchangeable,
+// so the difference of the statistic between the groups is compared with
+// its differences over random relabelings of the pooled data.
+type Permutation struct {
+	Statistic Statistic
+	// Observed is the statistic of x less the statistic of y.
+	Observed float64
+	// Replicates holds the differences of the relabeled groups.
+	Replicates  []float64
+	Alternative hypothesis.Alternative
+	// P counts the observed difference as one of the relabelings, so it
+	// is never zero.
+	P float64
+}
+
+// NewPermutation tests whether s differs between x and y, taking
+// o.Resamples random relabelings. The statistic must take one column.
+func NewPermutation(s Statistic, x, y []float64, alt hypothesis.Alternative, o Options) (Permutation, error) {
+	if s.Columns != 1 {
+		return Permutation{}, ErrColumns
+	}
+	if len(x) < 2 || len(y) < 2 {
+		return Permutation{}, ErrTooFew
+	}
+	pooled := append(append([]float64(nil), x...), y...)
+	diff := func(values []float64) float64 {
+		return s.Eval([][]float64{values[:len(x)]}) - s.Eval([][]float64{values[len(x):]})
+	}
+	observed := diff(append([]float64(nil), pooled...))
+	if math.IsNaN(observed) {
+		return Permutation{}, ErrUndefined
+	}
+
+	replicates := run(o, func() func(rnd *rand.Rand) float64 {
+		shuffled := make([]float64, len(pooled))
+		return func(rnd *rand.Rand) float64 {
+			copy(shuffled, pooled)
+			rnd.Shuffle(len(shuffled), func(i, j int) {
+				shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+			})
+			return diff(shuffled)
+		}
+	})
+
+	// Relabelings that give the observed difference in exact arithmetic
+	// may differ from it by rounding, and still count as extreme
+	tolerance := 1e-9 * math.Max(1, math.Abs(observed))
+	extreme := 0
+	for _, r := range replicates {
+		switch alt {
+		case hypothesis.Less:
+			if r <= observed+tolerance {
+				extreme++
+			}
+		case hypothesis.Greater:
+			if r >= observed-tolerance {
+				extreme++
+			}
+		default:
+			if math.Abs(r) >= math.Abs(observed)-tolerance {
+				extreme++
+			}
+		}
+	}
+
+	return Permutation{
+		Statistic:   s,
+		Observed:    observed,
+		Replicates:  replicates,
+		Alternative: alt,
+		P:           float64(extreme+1) / float64(len(replicates)+1),
+	}, nil
+}
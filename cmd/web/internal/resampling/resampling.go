@@ -0,0 +1,177 @@
+// Package resampling implements nonparametric inference by resampling the
+// data themselves: bootstrap confidence intervals for any registered
+// statistic and permutation tests for the difference between two groups.
+// The resamples are spread over goroutines, and the same seed gives the
+// same result whatever the number of workers.
+package resampling
+
+import (
+	"errors"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/random"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat"
+)
+
+var (
+	// ErrTooFew is returned when a sample is too small to resample.
+	ErrTooFew = errors.New("resampling: too few observations")
+	// ErrLength is returned when the columns of paired data differ in
+	// length.
+	ErrLength = errors.New("resampling: columns differ in length")
+	// ErrColumns is returned when a statistic is given the wrong number
+	// of columns.
+	ErrColumns = errors.New("resampling: wrong number of columns for the statistic")
+	// ErrUndefined is returned when the statistic is undefined for the
+	// data, such as the correlation of a constant column.
+	ErrUndefined = errors.New("resampling: the statistic is undefined for the data")
+	// ErrDegenerate is returned when the bootstrap replicates all lie on
+	// one side of the estimate, so no BCa interval exists.
+	ErrDegenerate = errors.New("resampling: the bootstrap distribution is degenerate")
+)
+
+// Statistic is a function of the data that can be resampled. The data are
+// given as columns of equal length, and a resample draws whole rows, so
+// pairs stay together.
+type Statistic struct {
+	// Name is the URL name of the statistic, such as "median".
+	Name  string
+	Title string
+	// Columns is the number of columns Eval takes.
+	Columns int
+	// Eval computes the statistic. It may reorder the values of the
+	// columns, and returns NaN where the statistic is undefined.
+	Eval func(columns [][]float64) float64
+}
+
+var statistics = map[string]Statistic{}
+
+func init() {
+	for _, s := range []Statistic{
+		{"mean", "Mean", 1, func(c [][]float64) float64 {
+			return stat.Mean(c[0], nil)
+		}},
+		{"median", "Median", 1, func(c [][]float64) float64 {
+			sort.Float64s(c[0])
+			return stat.Quantile(0.5, stat.Empirical, c[0], nil)
+		}},
+		{"std-dev", "Standard deviation", 1, func(c [][]float64) float64 {
+			return stat.StdDev(c[0], nil)
+		}},
+		{"correlation", "Correlation", 2, func(c [][]float64) float64 {
+			return stat.Correlation(c[0], c[1], nil)
+		}},
+		{"slope", "Regression slope", 2, func(c [][]float64) float64 {
+			// The slope is cov(x, y) / var(x), undefined for a constant x
+			if stat.Variance(c[0], nil) == 0 {
+				return math.NaN()
+			}
+			_, beta := stat.LinearRegression(c[0], c[1], nil, false)
+			return beta
+		}},
+	} {
+		Register(s)
+	}
+}
+
+// Register adds a statistic to the registry, replacing any statistic with
+// the same name.
+func Register(s Statistic) {
+	statistics[s.Name] = s
+}
+
+// Lookup returns the statistic with the given name.
+func Lookup(name string) (Statistic, bool) {
+	s, ok := statistics[name]
+	return s, ok
+}
+
+// Statistics returns every registered statistic, sorted by name.
+func Statistics() []Statistic {
+	result := make([]Statistic, 0, len(statistics))
+	for _, s := range statistics {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Options control how many resamples are drawn and by how many workers.
+type Options struct {
+	Resamples int
+	// Workers is the number of goroutines; less than 1 means one per CPU.
+	Workers int
+	Seed    uint64
+}
+
+// blockSize is the number of resamples drawn from one generator. Blocks
+// rather than workers own the generators, so the seed alone fixes the
+// result.
+const blockSize = 256
+
+// run fills a slice with o.Resamples replicates. Every worker calls
+// newWorker once for a draw function of its own, which may keep scratch
+// space between calls.
+func run(o Options, newWorker func() func(rnd *rand.Rand) float64) []float64 {
+	replicates := make([]float64, o.Resamples)
+	blocks := (o.Resamples + blockSize - 1) / blockSize
+	seeds := make([]uint64, blocks)
+	master := random.New(o.Seed)
+	for i := range seeds {
+		seeds[i] = master.Uint64()
+	}
+
+	workers := o.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, blocks)
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			draw := newWorker()
+			for b := range next {
+				rnd := random.New(seeds[b])
+				for i := b * blockSize; i < min((b+1)*blockSize, o.Resamples); i++ {
+					replicates[i] = draw(rnd)
+				}
+			}
+		}()
+	}
+	for b := 0; b < blocks; b++ {
+		next <- b
+	}
+	close(next)
+	wg.Wait()
+	return replicates
+}
+
+// columnsLen returns the common length of the columns s takes.
+func columnsLen(s Statistic, data [][]float64) (int, error) {
+	if len(data) != s.Columns {
+		return 0, ErrColumns
+	}
+	for _, c := range data[1:] {
+		if len(c) != len(data[0]) {
+			return 0, ErrLength
+		}
+	}
+	return len(data[0]), nil
+}
+
+// clone returns a copy of the columns, so Eval may reorder them.
+func clone(data [][]float64) [][]float64 {
+	result := make([][]float64, len(data))
+	for i, c := range data {
+		result[i] = append([]float64(nil), c...)
+	}
+	return result
+}
@@ -0,0 +1,193 @@
+package resampling
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/davidhalasz/gomath/cmd/web/internal/hypothesis"
+)
+
+func lookup(t *testing.T, name string) Statistic {
+	t.Helper()
+	s, ok := Lookup(name)
+	if !ok {
+		t.Fatalf("Lookup(%q) found no statistic", name)
+	}
+	return s
+}
+
+func TestStatistics(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns [][]float64
+		want    float64
+	}{
+		{"mean", [][]float64{{1, 2, 6}}, 3},
+		{"median", [][]float64{{5, 1, 3}}, 3},
+		{"std-dev", [][]float64{{1, 2, 3}}, 1},
+		{"correlation", [][]float64{{1, 2, 3}, {6, 4, 2}}, -1},
+		{"slope", [][]float64{{1, 2, 3}, {6, 4, 2}}, -2},
+		{"slope", [][]float64{{2, 2, 2}, {6, 4, 2}}, math.NaN()},
+	}
+	for _, tt := range tests {
+		got := lookup(t, tt.name).Eval(tt.columns)
+		if math.IsNaN(tt.want) != math.IsNaN(got) || !math.IsNaN(got) && math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s%v = %g, want %g", tt.name, tt.columns, got, tt.want)
+		}
+	}
+
+	all := Statistics()
+	if !sort.SliceIsSorted(all, func(i, j int) bool { return all[i].Name < all[j].Name }) {
+		t.Error("Statistics() is not sorted by name")
+	}
+}
+
+// The seed alone fixes the replicates, whatever the number of workers.
+func TestBootstrapReproducible(t *testing.T) {
+	data := [][]float64{{2.1, 3.4, 1.9, 5.6, 4.4, 3.3, 2.8, 4.0}}
+	var replicates [][]float64
+	for _, workers := range []int{1, 3, 0} {
+		b, err := NewBootstrap(lookup(t, "median"), data, Options{Resamples: 1000, Workers: workers, Seed: 7})
+		if err != nil {
+			t.Fatal(err)
+		}
+		replicates = append(replicates, b.Replicates)
+	}
+	for _, r := range replicates[1:] {
+		if !reflect.DeepEqual(r, replicates[0]) {
+			t.Fatal("the replicates depend on the number of workers")
+		}
+	}
+}
+
+// The bootstrap standard error of the mean approaches the plug-in
+// estimate, the biased standard deviation over the square root of n.
+func TestBootstrapMean(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	b, err := NewBootstrap(lookup(t, "mean"), [][]float64{x}, Options{Resamples: 20000, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := math.Sqrt(8.25 / 10); math.Abs(b.StdError()-want) > 0.02*want {
+		t.Errorf("StdError = %g, want about %g", b.StdError(), want)
+	}
+	if math.Abs(b.Bias()) > 0.02 {
+		t.Errorf("Bias = %g, want about 0", b.Bias())
+	}
+	if !sort.Float64sAreSorted(b.Replicates) || b.Undefined != 0 {
+		t.Errorf("replicates are not sorted or %d are undefined", b.Undefined)
+	}
+
+	// For the mean of symmetric data the BCa corrections vanish
+	p := b.Percentile(0.95)
+	bca, err := b.BCa(0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Contains(5.5) || math.Abs(bca.Lower-p.Lower) > 0.1 || math.Abs(bca.Upper-p.Upper) > 0.1 {
+		t.Errorf("percentile %+v and BCa %+v intervals differ", p, bca)
+	}
+}
+
+func TestBootstrapUndefinedReplicates(t *testing.T) {
+	data := [][]float64{{1, 2, 3}, {2, 1, 3}}
+	b, err := NewBootstrap(lookup(t, "correlation"), data, Options{Resamples: 500, Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A resample of one row repeated three times has no correlation
+	if b.Undefined == 0 || b.Undefined+len(b.Replicates) != 500 {
+		t.Errorf("Undefined = %d of %d, want some of 500", b.Undefined, b.Undefined+len(b.Replicates))
+	}
+}
+
+func TestBootstrapErrors(t *testing.T) {
+	// Resamples of 20 distinct values almost never keep all of them
+	// distinct, so every replicate lies below the estimate
+	distinct := Statistic{Name: "distinct", Columns: 1, Eval: func(c [][]float64) float64 {
+		seen := map[float64]bool{}
+		for _, v := range c[0] {
+			seen[v] = true
+		}
+		return float64(len(seen))
+	}}
+	twenty := make([]float64, 20)
+	for i := range twenty {
+		twenty[i] = float64(i)
+	}
+	b, err := NewBootstrap(distinct, [][]float64{twenty}, Options{Resamples: 500, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.BCa(0.95); err != ErrDegenerate {
+		t.Errorf("BCa error %v, want %v", err, ErrDegenerate)
+	}
+
+	tests := []struct {
+		name      string
+		statistic string
+		data      [][]float64
+		want      error
+	}{
+		{"columns", "mean", [][]float64{{1, 2}, {3, 4}}, ErrColumns},
+		{"length", "correlation", [][]float64{{1, 2, 3}, {3, 4}}, ErrLength},
+		{"one value", "mean", [][]float64{{1}}, ErrTooFew},
+		{"constant", "correlation", [][]float64{{1, 2, 3}, {4, 4, 4}}, ErrUndefined},
+	}
+	for _, tt := range tests {
+		if _, err := NewBootstrap(lookup(t, tt.statistic), tt.data, Options{Resamples: 10}); err != tt.want {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestPermutation(t *testing.T) {
+	mean := lookup(t, "mean")
+	x := []float64{10, 11, 12, 13, 14}
+	y := []float64{0, 1, 2, 3, 4}
+
+	// Only 1 of the 252 ways to split the ten values puts the five largest
+	// first
+	p, err := NewPermutation(mean, x, y, hypothesis.Greater, Options{Resamples: 5000, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Observed != 10 || p.P > 0.01 {
+		t.Errorf("observed %g, p %g; want 10 and p near 1/252", p.Observed, p.P)
+	}
+	p, err = NewPermutation(mean, x, y, hypothesis.Less, Options{Resamples: 5000, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.P < 0.99 {
+		t.Errorf("less: p = %g, want near 1", p.P)
+	}
+
+	// Equal groups give every relabeling a difference at least as extreme
+	p, err = NewPermutation(mean, []float64{1, 2, 3}, []float64{3, 2, 1}, hypothesis.TwoSided, Options{Resamples: 200, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.P != 1 {
+		t.Errorf("equal groups: p = %g, want 1", p.P)
+	}
+}
+
+func TestPermutationErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		statistic string
+		x, y      []float64
+		want      error
+	}{
+		{"columns", "correlation", []float64{1, 2}, []float64{3, 4}, ErrColumns},
+		{"one value", "mean", []float64{1}, []float64{3, 4}, ErrTooFew},
+	}
+	for _, tt := range tests {
+		if _, err := NewPermutation(lookup(t, tt.statistic), tt.x, tt.y, hypothesis.TwoSided, Options{Resamples: 10}); err != tt.want {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package timeseries
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ACF returns the sample autocorrelation of y at lags 0 to maxLag, indexed
+// by lag. Every lag is divided by the variance of the whole series, as
+// usual, which keeps the sequence positive definite.
+func ACF(y []float64, maxLag int) ([]float64, error) {
+	n := len(y)
+	if n < 3 {
+		return nil, ErrTooFew
+	}
+	if maxLag < 1 || maxLag >= n {
+		return nil, ErrLag
+	}
+
+	mean := 0.0
+	for _, v := range y {
+		mean += v
+	}
+	mean /= float64(n)
+	d := make([]float64, n)
+	ss := 0.0
+	for i, v := range y {
+		d[i] = v - mean
+		ss += d[i] * d[i]
+	}
+	if ss == 0 {
+		return nil, ErrConstant
+	}
+
+	acf := make([]float64, maxLag+1)
+	for k := range acf {
+		sum := 0.0
+		for t := k; t < n; t++ {
+			sum += d[t] * d[t-k]
+		}
+		acf[k] = sum / ss
+	}
+	return acf, nil
+}
+
+// PACF returns the partial autocorrelation at lags 1 to len(acf)-1 from
+// the autocorrelations acf, indexed by lag, so element 0 is 1. The partial
+// autocorrelation at lag k is the last coefficient of the best AR(k)
+// model, found from that of AR(k-1) by the Durbin-Levinson recursion.
+func PACF(acf []float64) []float64 {
+	pacf := make([]float64, len(acf))
+	pacf[0] = 1
+	if len(acf) < 2 {
+		return pacf
+	}
+
+	// phi holds the coefficients of the AR(k-1) model at lags 1 to k-1
+	phi := []float64{acf[1]}
+	pacf[1] = acf[1]
+	for k := 2; k < len(acf); k++ {
+		num, den := acf[k], 1.0
+		for j := 1; j < k; j++ {
+			num -= phi[j-1] * acf[k-j]
+			den -= phi[j-1] * acf[j]
+		}
+		kk := num / den
+		next := make([]float64, k)
+		for j := 1; j < k; j++ {
+			next[j-1] = phi[j-1] - kk*phi[k-j-1]
+		}
+		next[k-1] = kk
+		phi = next
+		pacf[k] = kk
+	}
+	return pacf
+}
+
+// Band returns the half width of the band that autocorrelations of white
+// noise of n values stay inside with probability level, ±z/√n. It is
+// the band of the partial autocorrelations at every lag.
+func Band(n int, level float64) float64 {
+	return z(level) / math.Sqrt(float64(n))
+}
+
+// BartlettBands returns the half width of the band around each
+// autocorrelation of acf, from a series of n values, under the hypothesis
+// that the autocorrelations beyond the lag before it are zero. Bartlett's
+// formula widens the band by the autocorrelations at the shorter lags;
+// element 0 is 0.
+func BartlettBands(acf []float64, n int, level float64) []float64 {
+	bands := make([]float64, len(acf))
+	sum := 0.0
+	for k := 1; k < len(acf); k++ {
+		bands[k] = z(level) * math.Sqrt((1+2*sum)/float64(n))
+		sum += acf[k] * acf[k]
+	}
+	return bands
+}
+
+// z returns the two sided normal quantile of level.
+func z(level float64) float64 {
+	return distuv.UnitNormal.Quantile((1 + level) / 2)
+}
@@ -0,0 +1,75 @@
+package timeseries
+
+import (
+	"math"
+	"testing"
+)
+
+// The autocorrelations are those of R's acf(1:10). The partial
+// autocorrelation at lag 2 is (r2 - r1^2) / (1 - r1^2).
+func TestACF(t *testing.T) {
+	y := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	acf, err := ACF(y, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{1, 0.7, 0.4121212, 0.1484848}; !nearAll(acf, want, 1e-6) {
+		t.Errorf("ACF = %v, want %v", acf, want)
+	}
+	pacf := PACF(acf)
+	if want := []float64{1, 0.7, -0.1527035, -0.1549067}; !nearAll(pacf, want, 1e-6) {
+		t.Errorf("PACF = %v, want %v", pacf, want)
+	}
+}
+
+func TestACFErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		y      []float64
+		maxLag int
+		want   error
+	}{
+		{"two values", []float64{1, 2}, 1, ErrTooFew},
+		{"lag 0", []float64{1, 2, 3}, 0, ErrLag},
+		{"lag n", []float64{1, 2, 3}, 3, ErrLag},
+		{"constant", []float64{4, 4, 4, 4}, 2, ErrConstant},
+	}
+	for _, tt := range tests {
+		if _, err := ACF(tt.y, tt.maxLag); err != tt.want {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+// An AR(1) process with coefficient phi has autocorrelations phi^k, and
+// its partial autocorrelations vanish beyond lag 1.
+func TestPACF(t *testing.T) {
+	tests := []struct {
+		name string
+		acf  []float64
+		want []float64
+	}{
+		{"lag 0 only", []float64{1}, []float64{1}},
+		{"AR(1)", []float64{1, 0.6, 0.36, 0.216, 0.1296}, []float64{1, 0.6, 0, 0, 0}},
+		{"AR(1) negative", []float64{1, -0.5, 0.25, -0.125}, []float64{1, -0.5, 0, 0}},
+		// AR(2) with coefficients 0.5 and 0.3: rho1 = 0.5/0.7 and
+		// rho2 = 0.5 rho1 + 0.3
+		{"AR(2)", []float64{1, 5.0 / 7, 0.5*5.0/7 + 0.3, 0.5*(0.5*5.0/7+0.3) + 0.3*5.0/7}, []float64{1, 5.0 / 7, 0.3, 0}},
+	}
+	for _, tt := range tests {
+		if got := PACF(tt.acf); !nearAll(got, tt.want, 1e-12) {
+			t.Errorf("%s: PACF = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBands(t *testing.T) {
+	if got := Band(100, 0.95); !near(got, 0.1959964, 1e-6) {
+		t.Errorf("Band(100, 0.95) = %g, want 0.1959964", got)
+	}
+	bands := BartlettBands([]float64{1, 0.5, 0.25}, 100, 0.95)
+	want := []float64{0, 0.1959964, 0.1959964 * math.Sqrt(1.5)}
+	if !nearAll(bands, want, 1e-6) {
+		t.Errorf("BartlettBands = %v, want %v", bands, want)
+	}
+}
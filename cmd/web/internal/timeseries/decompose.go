@@ -0,0 +1,116 @@
+package timeseries
+
+import "math"
+
+// Model is how the components of a series combine.
+type Model string
+
+const (
+	// Additive series are trend + seasonal + remainder, with seasonal
+	// swings of constant size.
+	Additive Model = "additive"
+	// Multiplicative series are trend · seasonal · remainder, with
+	// seasonal swings that grow with the trend.
+	Multiplicative Model = "multiplicative"
+)
+
+// Models lists every decomposition model.
+var Models = []Model{Additive, Multiplicative}
+
+// Decomposition splits a series into its trend, seasonal and remainder
+// components. The trend and remainder are NaN at the period/2 values at
+// each end, where the centered moving average has no full window.
+type Decomposition struct {
+	Model  Model
+	Period int
+	Trend  []float64
+	// Indices holds the seasonal effect of each position in the period,
+	// starting with the position of the first value. They sum to 0 for
+	// the additive model and average to 1 for the multiplicative one.
+	Indices   []float64
+	Seasonal  []float64
+	Remainder []float64
+}
+
+// Decompose splits y, which repeats every period values, into components
+// by the classical method: the trend is the centered moving average over
+// one period, and the seasonal effect of a position is the average of the
+// detrended values at it. y must hold at least two periods.
+func Decompose(y []float64, period int, model Model) (Decomposition, error) {
+	n := len(y)
+	if period < 2 {
+		return Decomposition{}, ErrPeriod
+	}
+	if n < 2*period {
+		return Decomposition{}, ErrTooFew
+	}
+	if model == Multiplicative {
+		for _, v := range y {
+			if v <= 0 {
+				return Decomposition{}, ErrNonPositive
+			}
+		}
+	}
+
+	// An even period is centered by averaging two neighboring windows,
+	// which gives the ends half weight
+	half := period / 2
+	trend := make([]float64, n)
+	for t := range trend {
+		if t < half || t >= n-half {
+			trend[t] = math.NaN()
+			continue
+		}
+		sum := 0.0
+		for j := t - half; j <= t+half; j++ {
+			sum += y[j]
+		}
+		if period%2 == 0 {
+			sum -= (y[t-half] + y[t+half]) / 2
+		}
+		trend[t] = sum / float64(period)
+	}
+
+	detrend := func(v, tr float64) float64 {
+		if model == Multiplicative {
+			return v / tr
+		}
+		return v - tr
+	}
+	indices := make([]float64, period)
+	counts := make([]int, period)
+	for t, v := range y {
+		if !math.IsNaN(trend[t]) {
+			indices[t%period] += detrend(v, trend[t])
+			counts[t%period]++
+		}
+	}
+	mean := 0.0
+	for i := range indices {
+		indices[i] /= float64(counts[i])
+		mean += indices[i] / float64(period)
+	}
+	// The effects are centered on their mean the same way the values are
+	// on the trend
+	for i := range indices {
+		indices[i] = detrend(indices[i], mean)
+	}
+
+	d := Decomposition{
+		Model:     model,
+		Period:    period,
+		Trend:     trend,
+		Indices:   indices,
+		Seasonal:  make([]float64, n),
+		Remainder: make([]float64, n),
+	}
+	for t, v := range y {
+		d.Seasonal[t] = indices[t%period]
+		if model == Multiplicative {
+			d.Remainder[t] = v / (trend[t] * d.Seasonal[t])
+		} else {
+			d.Remainder[t] = v - trend[t] - d.Seasonal[t]
+		}
+	}
+	return d, nil
+}
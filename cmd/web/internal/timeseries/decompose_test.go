@@ -0,0 +1,88 @@
+package timeseries
+
+import (
+	"math"
+	"testing"
+)
+
+// seasonal returns a linear trend of the given slope plus indices repeated
+// from the start, or times them for the multiplicative model.
+func seasonal(n int, slope float64, indices []float64, model Model) []float64 {
+	y := make([]float64, n)
+	for t := range y {
+		trend := 10 + slope*float64(t)
+		if model == Multiplicative {
+			y[t] = trend * indices[t%len(indices)]
+		} else {
+			y[t] = trend + indices[t%len(indices)]
+		}
+	}
+	return y
+}
+
+// The centered moving average recovers a linear trend exactly, so the
+// seasonal effects are recovered exactly and the remainder is zero.
+func TestDecompose(t *testing.T) {
+	tests := []struct {
+		name    string
+		slope   float64
+		indices []float64
+		model   Model
+	}{
+		{"additive odd period", 0.5, []float64{3, -1, -2}, Additive},
+		{"additive even period", 2, []float64{1, -1, 2, -2}, Additive},
+		{"multiplicative", 0, []float64{1.2, 0.8, 1.5, 0.5}, Multiplicative},
+	}
+	for _, tt := range tests {
+		period := len(tt.indices)
+		y := seasonal(4*period, tt.slope, tt.indices, tt.model)
+		d, err := Decompose(y, period, tt.model)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !nearAll(d.Indices, tt.indices, 1e-12) {
+			t.Errorf("%s: Indices = %v, want %v", tt.name, d.Indices, tt.indices)
+		}
+		half := period / 2
+		for i := range y {
+			if (i < half || i >= len(y)-half) != math.IsNaN(d.Trend[i]) {
+				t.Errorf("%s: Trend[%d] = %g, NaN only at the ends expected", tt.name, i, d.Trend[i])
+				continue
+			}
+			if math.IsNaN(d.Trend[i]) {
+				continue
+			}
+			remainder := 0.0
+			if tt.model == Multiplicative {
+				remainder = 1
+			}
+			if want := 10 + tt.slope*float64(i); !near(d.Trend[i], want, 1e-12) || !near(d.Remainder[i], remainder, 1e-12) {
+				t.Errorf("%s: at %d trend %g, remainder %g; want %g, %g", tt.name, i, d.Trend[i], d.Remainder[i], want, remainder)
+			}
+		}
+	}
+}
+
+func TestDecomposeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		y      []float64
+		period int
+		model  Model
+		want   error
+	}{
+		{"period 1", []float64{1, 2, 3, 4}, 1, Additive, ErrPeriod},
+		{"one period", []float64{1, 2, 3, 4, 5}, 3, Additive, ErrTooFew},
+		{"zero", []float64{1, 2, 0, 4}, 2, Multiplicative, ErrNonPositive},
+		{"negative", []float64{1, 2, -3, 4}, 2, Multiplicative, ErrNonPositive},
+	}
+	for _, tt := range tests {
+		if _, err := Decompose(tt.y, tt.period, tt.model); err != tt.want {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if _, err := Decompose([]float64{1, 2, 0, 4}, 2, Additive); err != nil {
+		t.Errorf("additive with a zero: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+// Package timeseries analyses values observed in time order: it smooths
+// them with moving averages, measures their autocorrelation and splits
+// them into trend, seasonal and remainder components.
+package timeseries
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrTooFew is returned when a series is too short for the analysis.
+	ErrTooFew = errors.New("timeseries: too few observations")
+	// ErrLength is returned when the times and values differ in length.
+	ErrLength = errors.New("timeseries: times and values differ in length")
+	// ErrDuplicate is returned when two values have the same time.
+	ErrDuplicate = errors.New("timeseries: duplicate time")
+	// ErrTime is returned for a time that is neither RFC 3339, a date nor
+	// Unix seconds.
+	ErrTime = errors.New("timeseries: unrecognized time")
+	// ErrWindow is returned for a moving average window that is not
+	// between 1 and the length of the series.
+	ErrWindow = errors.New("timeseries: window out of range")
+	// ErrSmoothing is returned for an exponential smoothing factor outside
+	// (0, 1].
+	ErrSmoothing = errors.New("timeseries: smoothing factor out of range")
+	// ErrLag is returned for a maximum lag that is not between 1 and the
+	// length of the series less one.
+	ErrLag = errors.New("timeseries: lag out of range")
+	// ErrConstant is returned when the values do not vary, so their
+	// autocorrelation is undefined.
+	ErrConstant = errors.New("timeseries: values are constant")
+	// ErrPeriod is returned for a seasonal period shorter than 2.
+	ErrPeriod = errors.New("timeseries: period must be at least 2")
+	// ErrNonPositive is returned when a multiplicative decomposition meets
+	// a value that is not positive.
+	ErrNonPositive = errors.New("timeseries: multiplicative model needs positive values")
+)
+
+// timeLayouts are the layouts ParseTime tries, most precise first.
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02", "2006-01"}
+
+// ParseTime reads a timestamp given in RFC 3339, as a date such as
+// "2023-04-01" or a month such as "2023-04", or as Unix seconds. Times
+// without a zone are UTC.
+func ParseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	if sec, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(sec) && !math.IsInf(sec, 0) {
+		whole, frac := math.Modf(sec)
+		return time.Unix(int64(whole), int64(frac*1e9)).UTC(), nil
+	}
+	return time.Time{}, ErrTime
+}
+
+// Series is a sequence of values in time order.
+type Series struct {
+	Times  []time.Time
+	Values []float64
+}
+
+// NewSeries returns the values ordered by their times, which must be
+// distinct.
+func NewSeries(times []time.Time, values []float64) (Series, error) {
+	if len(times) != len(values) {
+		return Series{}, ErrLength
+	}
+	order := make([]int, len(times))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return times[order[a]].Before(times[order[b]]) })
+
+	s := Series{Times: make([]time.Time, len(times)), Values: make([]float64, len(values))}
+	for i, j := range order {
+		s.Times[i], s.Values[i] = times[j], values[j]
+		if i > 0 && s.Times[i].Equal(s.Times[i-1]) {
+			return Series{}, ErrDuplicate
+		}
+	}
+	return s, nil
+}
+
+// SMA returns the simple moving average of y: the mean of the window
+// values ending at each point. The first window-1 points have no full
+// window and are NaN.
+func SMA(y []float64, window int) ([]float64, error) {
+	if window < 1 || window > len(y) {
+		return nil, ErrWindow
+	}
+	sma := make([]float64, len(y))
+	sum := 0.0
+	for i, v := range y {
+		sum += v
+		if i >= window {
+			sum -= y[i-window]
+		}
+		sma[i] = math.NaN()
+		if i >= window-1 {
+			sma[i] = sum / float64(window)
+		}
+	}
+	return sma, nil
+}
+
+// WMA returns the linearly weighted moving average of y, where the latest
+// of the window values ending at each point weighs window times as much
+// as the earliest. The first window-1 points are NaN.
+func WMA(y []float64, window int) ([]float64, error) {
+	if window < 1 || window > len(y) {
+		return nil, ErrWindow
+	}
+	total := float64(window*(window+1)) / 2
+	wma := make([]float64, len(y))
+	for i := range y {
+		if i < window-1 {
+			wma[i] = math.NaN()
+			continue
+		}
+		sum := 0.0
+		for j := 0; j < window; j++ {
+			sum += float64(window-j) * y[i-j]
+		}
+		wma[i] = sum / total
+	}
+	return wma, nil
+}
+
+// EMA returns the exponential moving average of y with smoothing factor
+// alpha, which starts at the first value and then moves the share alpha
+// of the way to each new value. A window of w points corresponds to alpha
+// = 2/(w+1).
+func EMA(y []float64, alpha float64) ([]float64, error) {
+	if len(y) == 0 {
+		return nil, ErrTooFew
+	}
+	if !(alpha > 0 && alpha <= 1) {
+		return nil, ErrSmoothing
+	}
+	ema := make([]float64, len(y))
+	ema[0] = y[0]
+	for i := 1; i < len(y); i++ {
+		ema[i] = ema[i-1] + alpha*(y[i]-ema[i-1])
+	}
+	return ema, nil
+}
@@ -0,0 +1,120 @@
+package timeseries
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// near reports whether got is within tol of want, counting two NaNs as
+// equal since they mark the points a moving average leaves out.
+func near(got, want, tol float64) bool {
+	if math.IsNaN(want) {
+		return math.IsNaN(got)
+	}
+	return math.Abs(got-want) <= tol*math.Max(1, math.Abs(want))
+}
+
+func nearAll(got, want []float64, tol float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range want {
+		if !near(got[i], want[i], tol) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2023-04-01T12:30:00+02:00", time.Date(2023, 4, 1, 10, 30, 0, 0, time.UTC)},
+		{"2023-04-01T12:30:15", time.Date(2023, 4, 1, 12, 30, 15, 0, time.UTC)},
+		{"2023-04-01T12:30", time.Date(2023, 4, 1, 12, 30, 0, 0, time.UTC)},
+		{"2023-04-01", time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"2023-04", time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"1680307200", time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"1.5", time.Unix(1, 5e8).UTC()},
+	}
+	for _, tt := range tests {
+		got, err := ParseTime(tt.in)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "yesterday", "2023-13-01", "NaN", "Inf"} {
+		if _, err := ParseTime(in); err != ErrTime {
+			t.Errorf("ParseTime(%q) error %v, want %v", in, err, ErrTime)
+		}
+	}
+}
+
+func TestNewSeries(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2023, 1, d, 0, 0, 0, 0, time.UTC) }
+	s, err := NewSeries([]time.Time{day(3), day(1), day(2)}, []float64{30, 10, 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []float64{10, 20, 30} {
+		if s.Values[i] != want || !s.Times[i].Equal(day(i+1)) {
+			t.Fatalf("NewSeries = %v, %v; want the values in time order", s.Times, s.Values)
+		}
+	}
+
+	if _, err := NewSeries([]time.Time{day(1), day(2), day(1)}, []float64{1, 2, 3}); err != ErrDuplicate {
+		t.Errorf("duplicate times: error %v, want %v", err, ErrDuplicate)
+	}
+	if _, err := NewSeries([]time.Time{day(1)}, []float64{1, 2}); err != ErrLength {
+		t.Errorf("lengths: error %v, want %v", err, ErrLength)
+	}
+}
+
+func TestMovingAverages(t *testing.T) {
+	nan := math.NaN()
+	y := []float64{1, 2, 3, 4, 5, 6}
+	tests := []struct {
+		name    string
+		average func() ([]float64, error)
+		want    []float64
+	}{
+		{"SMA 1", func() ([]float64, error) { return SMA(y, 1) }, y},
+		{"SMA 3", func() ([]float64, error) { return SMA(y, 3) }, []float64{nan, nan, 2, 3, 4, 5}},
+		{"SMA 6", func() ([]float64, error) { return SMA(y, 6) }, []float64{nan, nan, nan, nan, nan, 3.5}},
+		{"WMA 3", func() ([]float64, error) { return WMA(y, 3) }, []float64{nan, nan, 14.0 / 6, 20.0 / 6, 26.0 / 6, 32.0 / 6}},
+		{"EMA 1", func() ([]float64, error) { return EMA(y, 1) }, y},
+		{"EMA 0.5", func() ([]float64, error) { return EMA([]float64{4, 8, 0, 4}, 0.5) }, []float64{4, 6, 3, 3.5}},
+	}
+	for _, tt := range tests {
+		got, err := tt.average()
+		if err != nil || !nearAll(got, tt.want, 1e-12) {
+			t.Errorf("%s = %v, %v; want %v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestMovingAverageErrors(t *testing.T) {
+	y := []float64{1, 2, 3}
+	tests := []struct {
+		name    string
+		average func() ([]float64, error)
+		want    error
+	}{
+		{"SMA 0", func() ([]float64, error) { return SMA(y, 0) }, ErrWindow},
+		{"SMA too long", func() ([]float64, error) { return SMA(y, 4) }, ErrWindow},
+		{"WMA 0", func() ([]float64, error) { return WMA(y, 0) }, ErrWindow},
+		{"WMA too long", func() ([]float64, error) { return WMA(y, 4) }, ErrWindow},
+		{"EMA empty", func() ([]float64, error) { return EMA(nil, 0.5) }, ErrTooFew},
+		{"EMA 0", func() ([]float64, error) { return EMA(y, 0) }, ErrSmoothing},
+		{"EMA above 1", func() ([]float64, error) { return EMA(y, 1.5) }, ErrSmoothing},
+		{"EMA NaN", func() ([]float64, error) { return EMA(y, math.NaN()) }, ErrSmoothing},
+	}
+	for _, tt := range tests {
+		if _, err := tt.average(); err != tt.want {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
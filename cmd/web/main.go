@@ -39,11 +39,13 @@ func main() {
 func run() error {
 	inProduction := flag.Bool("production", true, "Application is in production")
 	useCache := flag.Bool("cache", true, "Use template cache")
+	maxUpload := flag.Int64("max-upload", 1<<20, "Maximum size of an uploaded dataset in bytes")
 
 	flag.Parse()
 
 	app.InProduction = *inProduction
 	app.UseCache = *useCache
+	app.MaxUploadBytes = *maxUpload
 
 	infoLog = log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime)
 	app.InfoLog = infoLog
@@ -15,12 +15,35 @@ func routes(app *config.AppConfig) http.Handler {
 	mux.Get("/statistics", handlers.StatisticsPage)
 	mux.Get("/statistics/mean", handlers.Mean)
 	mux.Get("/statistics/median", handlers.Median)
+	mux.Get("/statistics/quantiles", handlers.Quantiles)
+	mux.Get("/statistics/mode", handlers.Mode)
 	mux.Get("/statistics/std-deviation-variance", handlers.StdVar)
 	mux.Get("/statistics/pdf", handlers.PDF)
 	mux.Get("/statistics/binomial", handlers.Binomial)
 	mux.Get("/statistics/poisson", handlers.Poisson)
 	mux.Get("/statistics/covcor", handlers.CovCor)
+	mux.Get("/statistics/correlation", handlers.Correlation)
+	mux.Get("/statistics/t-test", handlers.TTest)
+	mux.Get("/statistics/chi-square-test", handlers.ChiSquareTest)
+	mux.Get("/statistics/anova", handlers.ANOVA)
+	mux.Get("/statistics/normality", handlers.Normality)
+	mux.Get("/statistics/confidence-interval", handlers.ConfidenceInterval)
+	mux.Get("/statistics/clt", handlers.CLT)
+	mux.Get("/statistics/bootstrap", handlers.Bootstrap)
+	mux.Get("/statistics/permutation-test", handlers.PermutationTest)
+	mux.Get("/statistics/distribution/{name}", handlers.Distribution)
+	mux.Get("/statistics/distribution/{name}/chart.{ext}", handlers.DistributionChart)
+	mux.Get("/statistics/distribution-fit", handlers.DistributionFit)
+	mux.Get("/statistics/bayes", handlers.Bayes)
 	mux.Get("/statistics/linear-regression", handlers.LinearRegression)
+	mux.Get("/statistics/polynomial-regression", handlers.PolynomialRegression)
+	mux.Get("/statistics/logistic-regression", handlers.LogisticRegression)
+	mux.Get("/statistics/time-series", handlers.TimeSeries)
+	mux.Post("/statistics/dataset", handlers.Dataset)
+	mux.Post("/statistics/multiple-regression", handlers.MultipleRegression)
+	mux.Get("/statistics/{topic}/chart.{ext}", handlers.Chart)
+
+	mux.Get("/api/openapi.json", handlers.OpenAPI)
 
 	mux.Get("/ai-basics", handlers.AiPage)
 	mux.Get("/ai-basics/b", handlers.CallDLS)
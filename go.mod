@@ -2,6 +2,13 @@ module github.com/davidhalasz/gomath
 
 go 1.21.4
 
+require (
+	github.com/go-chi/chi/v5 v5.0.11
+	golang.org/x/exp v0.0.0-20230801115018-d63ba01acd4b
+	gonum.org/v1/gonum v0.14.0
+	gonum.org/v1/plot v0.14.0
+)
+
 require (
 	gioui.org v0.2.0 // indirect
 	gioui.org/cpu v0.0.0-20220412190645-f1e9e8c3b1f7 // indirect
@@ -11,7 +18,6 @@ require (
 	github.com/ajstarks/svgo v0.0.0-20211024235047-1546f124cd8b // indirect
 	github.com/andybalholm/stroke v0.0.0-20221221101821-bd29b49d73f0 // indirect
 	github.com/campoy/embedmd v1.0.0 // indirect
-	github.com/go-chi/chi/v5 v5.0.11 // indirect
 	github.com/go-fonts/liberation v0.3.1 // indirect
 	github.com/go-latex/latex v0.0.0-20230307184459-12ec69307ad9 // indirect
 	github.com/go-pdf/fpdf v0.8.0 // indirect
@@ -22,13 +28,11 @@ require (
 	github.com/openacid/slimarray v0.1.3 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/sajari/regression v1.0.1 // indirect
-	golang.org/x/exp v0.0.0-20230801115018-d63ba01acd4b // indirect
 	golang.org/x/exp/shiny v0.0.0-20230801115018-d63ba01acd4b // indirect
 	golang.org/x/image v0.11.0 // indirect
 	golang.org/x/sys v0.11.0 // indirect
 	golang.org/x/text v0.12.0 // indirect
-	gonum.org/v1/gonum v0.14.0 // indirect
-	gonum.org/v1/plot v0.14.0 // indirect
+	golang.org/x/tools v0.7.0 // indirect
 	google.golang.org/protobuf v1.25.0 // indirect
 	rsc.io/pdf v0.1.1 // indirect
 )